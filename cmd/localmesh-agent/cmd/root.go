@@ -3,11 +3,14 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -47,6 +50,8 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(registerCmd)
 	rootCmd.AddCommand(unregisterCmd)
+	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(discoverCmd)
 	rootCmd.AddCommand(statusCmd)
 }
 
@@ -70,6 +75,7 @@ var registerCmd = &cobra.Command{
 		ip, _ := cmd.Flags().GetString("ip")
 		description, _ := cmd.Flags().GetString("description")
 		keepAlive, _ := cmd.Flags().GetBool("keep-alive")
+		streaming, _ := cmd.Flags().GetBool("streaming")
 
 		if port <= 0 {
 			return fmt.Errorf("--port is required")
@@ -96,6 +102,7 @@ var registerCmd = &cobra.Command{
 			"port":        port,
 			"ip":          ip,
 			"description": description,
+			"streaming":   streaming,
 		}
 
 		jsonBody, _ := json.Marshal(reqBody)
@@ -112,6 +119,15 @@ var registerCmd = &cobra.Command{
 		json.NewDecoder(resp.Body).Decode(&result)
 
 		if resp.StatusCode != http.StatusOK {
+			if fields, ok := result["fields"].([]interface{}); ok && len(fields) > 0 {
+				var b strings.Builder
+				b.WriteString("registration failed:")
+				for _, f := range fields {
+					fe, _ := f.(map[string]interface{})
+					fmt.Fprintf(&b, "\n  • %v: %v", fe["field"], fe["message"])
+				}
+				return errors.New(b.String())
+			}
 			if errMsg, ok := result["error"].(string); ok {
 				return fmt.Errorf("registration failed: %s", errMsg)
 			}
@@ -164,6 +180,70 @@ var unregisterCmd = &cobra.Command{
 	},
 }
 
+var renameCmd = &cobra.Command{
+	Use:   "rename [old-name] [new-name]",
+	Short: "Rename a registered service",
+	Long: `Rename a registered service in place. The service is re-advertised under
+the new .local hostname and the old hostname is withdrawn.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName, newName := args[0], args[1]
+
+		server, err := getServer()
+		if err != nil {
+			return err
+		}
+
+		reqBody := map[string]string{"name": oldName, "new_name": newName}
+		jsonBody, _ := json.Marshal(reqBody)
+
+		url := fmt.Sprintf("http://%s/api/v1/services/rename", server)
+		resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonBody))
+		if err != nil {
+			return fmt.Errorf("failed to rename: %w", err)
+		}
+		defer resp.Body.Close()
+
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+
+		if resp.StatusCode != http.StatusOK {
+			if errMsg, ok := result["error"].(string); ok {
+				return fmt.Errorf("rename failed: %s", errMsg)
+			}
+			return fmt.Errorf("rename failed: status %d", resp.StatusCode)
+		}
+
+		fmt.Printf("✅ Service %s renamed to %s\n", oldName, newName)
+		fmt.Printf("   Hostname: %s\n", result["hostname"])
+		fmt.Printf("   URL:      %s\n", result["url"])
+		return nil
+	},
+}
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List LocalMesh servers advertised on the network",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		servers := discoverServers(2*time.Second, time.Second)
+		if len(servers) == 0 {
+			fmt.Println("No LocalMesh servers found")
+			return nil
+		}
+
+		fmt.Printf("LocalMesh servers (%d):\n", len(servers))
+		for _, srv := range servers {
+			state := "✅ reachable"
+			if !srv.Reachable {
+				state = "❌ unreachable"
+			}
+			fmt.Printf("  • %s  %s\n", srv.Addr, state)
+			fmt.Printf("    Name: %s\n", srv.Name)
+		}
+		return nil
+	},
+}
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show status of registered services",
@@ -206,6 +286,7 @@ func init() {
 	registerCmd.Flags().String("ip", "", "IP address (auto-detected if not set)")
 	registerCmd.Flags().StringP("description", "d", "", "Service description")
 	registerCmd.Flags().Bool("keep-alive", false, "Keep running and unregister on exit")
+	registerCmd.Flags().Bool("streaming", false, "Forward responses without buffering (SSE, long polling)")
 	registerCmd.MarkFlagRequired("port")
 }
 
@@ -247,18 +328,49 @@ func getServer() (string, error) {
 }
 
 func discoverLocalMesh() (string, error) {
+	servers := discoverServers(2*time.Second, time.Second)
+	if len(servers) == 0 {
+		return "", fmt.Errorf("no LocalMesh server found")
+	}
+
+	for _, srv := range servers {
+		if srv.Reachable {
+			return srv.Addr, nil
+		}
+	}
+
+	return "", fmt.Errorf("found %d LocalMesh server(s) via mDNS but none are reachable (stale cache?)", len(servers))
+}
+
+// browseMDNS runs an mDNS query; tests replace it
+var browseMDNS = mdns.Query
+
+// discoveredServer is a LocalMesh server advertised via mDNS
+type discoveredServer struct {
+	Name      string
+	Addr      string
+	Reachable bool
+}
+
+// discoverServers browses mDNS for LocalMesh servers and probes each one
+// with a TCP dial, since mDNS caches can keep advertising nodes that are gone
+func discoverServers(browseTimeout, probeTimeout time.Duration) []discoveredServer {
 	entriesCh := make(chan *mdns.ServiceEntry, 10)
-	var server string
-	var mu sync.Mutex
+	var servers []discoveredServer
+	seen := make(map[string]bool)
 	done := make(chan struct{})
 
 	go func() {
 		for entry := range entriesCh {
-			mu.Lock()
-			if server == "" && entry.Port > 0 && len(entry.AddrV4) > 0 {
-				server = fmt.Sprintf("%s:%d", entry.AddrV4, entry.Port)
+			if entry.Port <= 0 || len(entry.AddrV4) == 0 {
+				continue
+			}
+			addr := net.JoinHostPort(entry.AddrV4.String(), strconv.Itoa(entry.Port))
+			if seen[addr] {
+				continue
 			}
-			mu.Unlock()
+			seen[addr] = true
+			servers = append(servers, discoveredServer{Name: entry.Name, Addr: addr})
 		}
 		close(done)
 	}()
@@ -266,21 +378,35 @@ func discoverLocalMesh() (string, error) {
 	params := &mdns.QueryParam{
 		Service: "_localmesh._tcp",
 		Domain:  "local",
-		Timeout: 2 * time.Second,
+		Timeout: browseTimeout,
 		Entries: entriesCh,
 	}
 
-	_ = mdns.Query(params)
+	_ = browseMDNS(params)
 	close(entriesCh)
 	<-done
 
-	mu.Lock()
-	defer mu.Unlock()
-	if server == "" {
-		return "", fmt.Errorf("no LocalMesh server found")
+	var wg sync.WaitGroup
+	for i := range servers {
+		wg.Add(1)
+		go func(srv *discoveredServer) {
+			defer wg.Done()
+			srv.Reachable = probeReachable(srv.Addr, probeTimeout)
+		}(&servers[i])
 	}
+	wg.Wait()
 
-	return server, nil
+	return servers
+}
+
+// probeReachable reports whether a TCP connection to addr succeeds within timeout
+func probeReachable(addr string, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
 }
 
 func getOutboundIP() (string, error) {
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/mdns"
+)
+
+// liveAndClosedAddrs returns the address of a live listener and one nothing listens on
+func liveAndClosedAddrs(t *testing.T) (live, closed *net.TCPAddr) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	gone, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone.Close()
+
+	return ln.Addr().(*net.TCPAddr), gone.Addr().(*net.TCPAddr)
+}
+
+func TestProbeReachable(t *testing.T) {
+	live, closed := liveAndClosedAddrs(t)
+
+	if !probeReachable(live.String(), time.Second) {
+		t.Errorf("listening address %s reported unreachable", live)
+	}
+	if probeReachable(closed.String(), time.Second) {
+		t.Errorf("closed port %s reported reachable", closed)
+	}
+}
+
+func TestDiscoverServersProbesEachEntry(t *testing.T) {
+	live, closed := liveAndClosedAddrs(t)
+
+	orig := browseMDNS
+	browseMDNS = func(p *mdns.QueryParam) error {
+		if p.Service != "_localmesh._tcp" {
+			t.Errorf("browsed %q", p.Service)
+		}
+		p.Entries <- &mdns.ServiceEntry{Name: "stale", AddrV4: closed.IP, Port: closed.Port}
+		p.Entries <- &mdns.ServiceEntry{Name: "live", AddrV4: live.IP, Port: live.Port}
+		p.Entries <- &mdns.ServiceEntry{Name: "live-again", AddrV4: live.IP, Port: live.Port}
+		p.Entries <- &mdns.ServiceEntry{Name: "no-port", AddrV4: live.IP}
+		return nil
+	}
+	t.Cleanup(func() { browseMDNS = orig })
+
+	servers := discoverServers(time.Second, time.Second)
+
+	want := []discoveredServer{
+		{Name: "stale", Addr: net.JoinHostPort("127.0.0.1", strconv.Itoa(closed.Port)), Reachable: false},
+		{Name: "live", Addr: net.JoinHostPort("127.0.0.1", strconv.Itoa(live.Port)), Reachable: true},
+	}
+	if len(servers) != len(want) {
+		t.Fatalf("discovered %+v, want %+v", servers, want)
+	}
+	for i := range want {
+		if servers[i] != want[i] {
+			t.Errorf("server %d = %+v, want %+v", i, servers[i], want[i])
+		}
+	}
+
+	addr, err := discoverLocalMesh()
+	if err != nil || addr != want[1].Addr {
+		t.Errorf("discoverLocalMesh = %q, %v; want the reachable %s", addr, err, want[1].Addr)
+	}
+}
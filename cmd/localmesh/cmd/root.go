@@ -1,11 +1,20 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/FABLOUSFALCON/localmesh/internal/config"
 	"github.com/FABLOUSFALCON/localmesh/internal/core"
+	"github.com/FABLOUSFALCON/localmesh/internal/gateway"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +23,8 @@ var (
 	commitStr  = "none"
 	dateStr    = "unknown"
 	cfgFile    string
+	verbosity  int
+	debug      bool
 )
 
 var rootCmd = &cobra.Command{
@@ -28,6 +39,30 @@ Get started:
   localmesh status    Check running services
 
 Use localmesh-agent to register services from any device on the network.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		slog.SetLogLoggerLevel(verboseLevel(slog.LevelInfo))
+	},
+}
+
+// verboseLevel lowers base by one level per -v, down to debug. --debug
+// always means debug.
+func verboseLevel(base slog.Level) slog.Level {
+	if debug {
+		return slog.LevelDebug
+	}
+	return max(base-slog.Level(4*verbosity), slog.LevelDebug)
+}
+
+// loadConfig loads the config file with -v and --debug applied to its log level
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if debug || verbosity > 0 {
+		cfg.Log.Level = strings.ToLower(verboseLevel(cfg.Log.SlogLevel()).String())
+	}
+	return cfg, nil
 }
 
 func Execute() error {
@@ -42,13 +77,41 @@ func SetVersionInfo(version, commit, date string) {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ./localmesh.yaml)")
-	rootCmd.PersistentFlags().Bool("debug", false, "enable debug logging")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logging")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "lower the log level one step per -v (at the default info level, -v shows debug)")
 
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(stopCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configSchemaCmd)
+
+	startCmd.Flags().Bool("migrate", false, "upgrade the config file to the current schema before starting")
+	startCmd.Flags().Bool("require-network", false, "fail to start if the node has no network or mDNS advertisement fails")
+	stopCmd.Flags().Duration("timeout", 35*time.Second, "how long to wait for a graceful exit")
+	statusCmd.Flags().Bool("json", false, "output status as JSON")
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect LocalMesh configuration",
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print a JSON Schema for localmesh.yaml",
+	Long: `Print a JSON Schema describing localmesh.yaml, for editor validation
+and autocompletion. For example, with the YAML language server:
+
+  localmesh config schema > localmesh.schema.json
+  # yaml-language-server: $schema=./localmesh.schema.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(config.Schema())
+	},
 }
 
 var versionCmd = &cobra.Command{
@@ -76,25 +139,7 @@ var initCmd = &cobra.Command{
 
 		// Create default config if not exists
 		if _, err := os.Stat("localmesh.yaml"); os.IsNotExist(err) {
-			defaultConfig := `# LocalMesh Configuration
-node:
-  name: "localmesh-node"
-  zone: "default"
-
-gateway:
-  host: "0.0.0.0"
-  port: 8080
-  hostname: "campus"
-
-grpc:
-  enabled: true
-  port: 9000
-
-log:
-  level: "info"
-  format: "text"
-`
-			if err := os.WriteFile("localmesh.yaml", []byte(defaultConfig), 0644); err != nil {
+			if err := os.WriteFile("localmesh.yaml", []byte(config.DefaultYAML), 0644); err != nil {
 				return fmt.Errorf("failed to create config: %w", err)
 			}
 			fmt.Println("✅ Created localmesh.yaml")
@@ -114,9 +159,23 @@ var startCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("🚀 Starting LocalMesh...")
 
-		cfg, err := config.Load(cfgFile)
+		if migrateCfg, _ := cmd.Flags().GetBool("migrate"); migrateCfg {
+			path, warnings, err := config.Migrate(cfgFile)
+			if err != nil {
+				return fmt.Errorf("migrating config: %w", err)
+			}
+			for _, w := range warnings {
+				fmt.Printf("   ⚠️  %s\n", w)
+			}
+			fmt.Printf("✅ Migrated %s to schema version %d\n", path, config.SchemaVersion)
+		}
+
+		cfg, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
+			return err
+		}
+		if requireNetwork, _ := cmd.Flags().GetBool("require-network"); requireNetwork {
+			cfg.Network.Required = true
 		}
 
 		framework, err := core.New(cfg)
@@ -130,11 +189,7 @@ var startCmd = &cobra.Command{
 
 		fmt.Println("✅ LocalMesh is running!")
 		fmt.Printf("   API:   http://%s\n", cfg.GatewayAddr())
-		proxyPort := cfg.Gateway.ProxyPort
-		if proxyPort == 0 {
-			proxyPort = 8081
-		}
-		fmt.Printf("   Proxy: http://%s:%d (for *.local routing)\n", cfg.Gateway.Host, proxyPort)
+		fmt.Printf("   Proxy: %s\n", gateway.ServiceURL(cfg.MDNSDomain(), cfg.ProxyPort(), "<service>"))
 		fmt.Println("\nPress Ctrl+C to stop...")
 
 		framework.Wait()
@@ -143,11 +198,40 @@ var startCmd = &cobra.Command{
 }
 
 var stopCmd = &cobra.Command{
-	Use:   "stop",
-	Short: "Stop the LocalMesh server",
+	Use:          "stop",
+	Short:        "Stop the LocalMesh server",
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Stopping LocalMesh...")
-		// TODO: Implement proper stop via PID file or socket
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		pid, err := core.ReadPID(cfg.PIDFile())
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Stopping LocalMesh (pid %d)...\n", pid)
+
+		proc, err := os.FindProcess(pid)
+		if err != nil {
+			return fmt.Errorf("finding process %d: %w", pid, err)
+		}
+		if err := proc.Signal(syscall.SIGTERM); err != nil {
+			return fmt.Errorf("signalling process %d: %w", pid, err)
+		}
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		deadline := time.Now().Add(timeout)
+		for core.ProcessAlive(pid) {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("LocalMesh (pid %d) did not exit within %s", pid, timeout)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		fmt.Println("✅ LocalMesh stopped")
 		return nil
 	},
 }
@@ -156,16 +240,95 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show LocalMesh status",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load(cfgFile)
+		cfg, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
+			return err
 		}
 
-		fmt.Printf("LocalMesh Status\n")
-		fmt.Printf("  HTTP: http://%s\n", cfg.GatewayAddr())
+		report := statusReport{
+			HTTP: fmt.Sprintf("http://%s", cfg.GatewayAddr()),
+		}
 		if cfg.GRPC.Enabled {
-			fmt.Printf("  gRPC: %s\n", cfg.GRPCAddr())
+			report.GRPC = cfg.GRPCAddr()
+		}
+
+		base := fmt.Sprintf("http://%s", dialAddr(cfg.Gateway.Host, cfg.Gateway.Port))
+		client := &http.Client{Timeout: 2 * time.Second}
+
+		var status struct {
+			Uptime   string `json:"uptime"`
+			Services int    `json:"services"`
+		}
+		var stats struct {
+			ServicesHealthy int   `json:"services_healthy"`
+			RequestsTotal   int64 `json:"requests_total"`
+			RequestsActive  int64 `json:"requests_active"`
+		}
+		if err := getJSON(client, base+"/api/v1/status", &status); err == nil {
+			report.Running = true
+			report.Uptime = status.Uptime
+			report.Services = status.Services
+			if err := getJSON(client, base+"/api/v1/stats", &stats); err == nil {
+				report.Healthy = stats.ServicesHealthy
+				report.Requests = stats.RequestsTotal
+				report.Active = stats.RequestsActive
+			}
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		}
+
+		fmt.Printf("LocalMesh Status\n")
+		if report.Running {
+			fmt.Printf("  Status:   Running\n")
+			fmt.Printf("  Uptime:   %s\n", report.Uptime)
+			fmt.Printf("  Services: %d (%d healthy)\n", report.Services, report.Healthy)
+			fmt.Printf("  Requests: %d (%d active)\n", report.Requests, report.Active)
+		} else {
+			fmt.Printf("  Status:   Not Running\n")
+		}
+		fmt.Printf("  HTTP: %s\n", report.HTTP)
+		if report.GRPC != "" {
+			fmt.Printf("  gRPC: %s\n", report.GRPC)
 		}
 		return nil
 	},
 }
+
+// statusReport is the output of 'localmesh status'
+type statusReport struct {
+	Running  bool   `json:"running"`
+	Uptime   string `json:"uptime,omitempty"`
+	Services int    `json:"services"`
+	Healthy  int    `json:"services_healthy"`
+	Requests int64  `json:"requests_total"`
+	Active   int64  `json:"requests_active"`
+	HTTP     string `json:"http"`
+	GRPC     string `json:"grpc,omitempty"`
+}
+
+// dialAddr returns an address for reaching a local listener, replacing
+// wildcard hosts with loopback
+func dialAddr(host string, port int) string {
+	switch host {
+	case "", "0.0.0.0", "::":
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
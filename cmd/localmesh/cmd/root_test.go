@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// resetVerbosity restores the flag variables and default logger after a test
+func resetVerbosity(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		verbosity, debug, cfgFile = 0, false, ""
+		slog.SetLogLoggerLevel(slog.LevelInfo)
+		log.SetOutput(os.Stderr)
+	})
+}
+
+func TestVerboseFlagEnablesDebugOutput(t *testing.T) {
+	resetVerbosity(t)
+
+	for _, tt := range []struct {
+		args  []string
+		debug bool
+	}{
+		{[]string{"version"}, false},
+		{[]string{"-v", "version"}, true},
+		{[]string{"-vv", "version"}, true},
+		{[]string{"--debug", "version"}, true},
+	} {
+		verbosity, debug = 0, false
+		rootCmd.SetArgs(tt.args)
+		rootCmd.SetOut(io.Discard)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+
+		var out bytes.Buffer
+		log.SetOutput(&out)
+		slog.Debug("probe")
+		if got := strings.Contains(out.String(), "DEBUG probe"); got != tt.debug {
+			t.Errorf("%v: debug output = %v, want %v", tt.args, got, tt.debug)
+		}
+	}
+}
+
+func TestLoadConfigAppliesVerbosity(t *testing.T) {
+	resetVerbosity(t)
+	dir := t.TempDir()
+	t.Chdir(dir) // Load creates the default ./data directories
+	cfgFile = filepath.Join(dir, "localmesh.yaml")
+	if err := os.WriteFile(cfgFile, []byte("log:\n  level: warn\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		verbosity int
+		debug     bool
+		want      string
+	}{
+		{0, false, "warn"},
+		{1, false, "info"},
+		{2, false, "debug"},
+		{5, false, "debug"},
+		{0, true, "debug"},
+	} {
+		verbosity, debug = tt.verbosity, tt.debug
+		cfg, err := loadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Log.Level != tt.want {
+			t.Errorf("verbosity %d debug %v: level %q, want %q", tt.verbosity, tt.debug, cfg.Log.Level, tt.want)
+		}
+	}
+}
@@ -4,10 +4,14 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/spf13/viper"
@@ -15,6 +19,7 @@ import (
 
 // Config holds all LocalMesh configuration
 type Config struct {
+	Version  int             `mapstructure:"config_version"`
 	Node     NodeConfig      `mapstructure:"node"`
 	Network  NetworkConfig   `mapstructure:"network"`
 	Storage  StorageConfig   `mapstructure:"storage"`
@@ -25,9 +30,15 @@ type Config struct {
 	Log      LogConfig       `mapstructure:"log"`
 	Zones    []ZoneConfig    `mapstructure:"zones"`
 	Services []ServiceConfig `mapstructure:"services"`
+
+	// Warnings collects non-fatal problems found while loading, such as
+	// deprecated keys that were migrated
+	Warnings []string `mapstructure:"-"`
 }
 
-// ServiceConfig defines an external service registration
+// ServiceConfig defines an external service registration.
+// URL may use {{.NodeIP}} and {{.Zone}}, resolved by ResolveServices to this
+// node's primary LAN address and configured zone.
 type ServiceConfig struct {
 	Name        string   `mapstructure:"name"`
 	URL         string   `mapstructure:"url"`
@@ -47,6 +58,7 @@ type ZoneConfig struct {
 	BSSIDs      []string `mapstructure:"bssids"`
 	Description string   `mapstructure:"description"`
 	Priority    int      `mapstructure:"priority"`
+	RateLimit   int      `mapstructure:"rate_limit"` // Overrides security.rate_limit for clients in Subnets
 }
 
 // NodeConfig identifies this node in the mesh
@@ -69,6 +81,42 @@ type NetworkConfig struct {
 	HealthCheckPeriod time.Duration `mapstructure:"health_check_period"`
 	Interfaces        []string      `mapstructure:"interfaces"`
 	AllowedSubnets    []string      `mapstructure:"allowed_subnets"`
+	QuietHours        QuietHours    `mapstructure:"quiet_hours"`
+	Required          bool          `mapstructure:"required"` // Refuse to start without a network identity
+}
+
+// QuietHours is a daily window ("HH:MM" local time) during which mDNS
+// advertisement is paused. Leave both empty to advertise around the clock.
+type QuietHours struct {
+	Start string `mapstructure:"start"`
+	End   string `mapstructure:"end"`
+}
+
+// Enabled reports whether a quiet window is configured
+func (q QuietHours) Enabled() bool {
+	return q.Start != "" || q.End != ""
+}
+
+// Offsets returns the window's start and end as offsets from local midnight
+func (q QuietHours) Offsets() (start, end time.Duration, err error) {
+	if start, err = parseClock(q.Start); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(q.End); err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("network.quiet_hours start and end are both %s", q.Start)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid network.quiet_hours time %q (want HH:MM)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
 }
 
 // StorageConfig for database paths
@@ -84,30 +132,37 @@ type StorageConfig struct {
 
 // SecurityConfig for authentication and encryption
 type SecurityConfig struct {
-	TokenTTL        time.Duration `mapstructure:"token_ttl"`
-	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
-	KeyPath         string        `mapstructure:"key_path"`
-	RequireZoneAuth bool          `mapstructure:"require_zone_auth"`
-	RateLimit       int           `mapstructure:"rate_limit"`
-	RateLimitBurst  int           `mapstructure:"rate_limit_burst"`
-	RateLimitWindow time.Duration `mapstructure:"rate_limit_window"`
-	MaxSessions     int           `mapstructure:"max_sessions"`
+	TokenTTL         time.Duration `mapstructure:"token_ttl"`
+	RefreshTokenTTL  time.Duration `mapstructure:"refresh_token_ttl"`
+	KeyPath          string        `mapstructure:"key_path"`
+	RequireZoneAuth  bool          `mapstructure:"require_zone_auth"`
+	RateLimitEnabled bool          `mapstructure:"rate_limit_enabled"` // Throttle gateway and proxy requests per client
+	RateLimit        int           `mapstructure:"rate_limit"`
+	RateLimitBurst   int           `mapstructure:"rate_limit_burst"`
+	RateLimitWindow  time.Duration `mapstructure:"rate_limit_window"`
+	MaxSessions      int           `mapstructure:"max_sessions"`
 }
 
 // GatewayConfig for HTTP gateway
 type GatewayConfig struct {
-	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
-	ProxyPort    int           `mapstructure:"proxy_port"` // Reverse proxy port (default 8081)
-	Hostname     string        `mapstructure:"hostname"`   // .local hostname (e.g., "campus" → campus.local)
-	TLSEnabled   bool          `mapstructure:"tls_enabled"`
-	CertFile     string        `mapstructure:"cert_file"`
-	KeyFile      string        `mapstructure:"key_file"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
-	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
-	MaxBodySize  int64         `mapstructure:"max_body_size"`
-	CORSOrigins  []string      `mapstructure:"cors_origins"`
+	Host               string        `mapstructure:"host"`
+	Port               int           `mapstructure:"port"`
+	ProxyPort          int           `mapstructure:"proxy_port"` // Reverse proxy port (default 8081)
+	Hostname           string        `mapstructure:"hostname"`   // .local hostname (e.g., "campus" → campus.local)
+	TLSEnabled         bool          `mapstructure:"tls_enabled"`
+	CertFile           string        `mapstructure:"cert_file"`
+	KeyFile            string        `mapstructure:"key_file"`
+	ReadTimeout        time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout       time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout        time.Duration `mapstructure:"idle_timeout"`
+	MaxBodySize        int64         `mapstructure:"max_body_size"`
+	CORSOrigins        []string      `mapstructure:"cors_origins"`
+	MetricsEnabled     bool          `mapstructure:"metrics_enabled"`     // Expose Prometheus metrics on /metrics
+	MaxConnections     int           `mapstructure:"max_connections"`     // Concurrent connection cap, 0 = unlimited
+	ProxyRetries       int           `mapstructure:"proxy_retries"`       // Retries for failed GET/HEAD proxy requests
+	ProxyBackoff       time.Duration `mapstructure:"proxy_backoff"`       // Initial delay between proxy retries
+	CompressionEnabled bool          `mapstructure:"compression_enabled"` // Gzip compressible API and proxy responses (off by default)
+	DrainTimeout       time.Duration `mapstructure:"drain_timeout"`       // Wait for in-flight proxy requests on stop
 }
 
 // GRPCConfig for agent gRPC server
@@ -135,6 +190,22 @@ type LogConfig struct {
 	Format string `mapstructure:"format"`
 	Output string `mapstructure:"output"`
 	File   string `mapstructure:"file"`
+	// BufferLines is how many recent records are kept in memory for
+	// GET /api/v1/logs (0 disables the buffer)
+	BufferLines int `mapstructure:"buffer_lines"`
+}
+
+// SlogLevel returns the configured level, defaulting to info
+func (l LogConfig) SlogLevel() slog.Level {
+	switch l.Level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
 }
 
 var (
@@ -142,38 +213,50 @@ var (
 	cfgMu sync.RWMutex
 )
 
-// Load reads configuration from file and environment
-func Load(configPath string) (*Config, error) {
-	v := viper.New()
-	setDefaults(v)
+// DefaultYAML is the starter localmesh.yaml written by localmesh init
+const DefaultYAML = `# LocalMesh Configuration
+config_version: 1
 
-	if configPath != "" {
-		v.SetConfigFile(configPath)
-	} else {
-		v.SetConfigName("localmesh")
-		v.SetConfigType("yaml")
-		v.AddConfigPath(".")
-		v.AddConfigPath("./configs")
-		v.AddConfigPath("/etc/localmesh")
-		if home, _ := os.UserHomeDir(); home != "" {
-			v.AddConfigPath(filepath.Join(home, ".config", "localmesh"))
-		}
-	}
+node:
+  name: "localmesh-node"
+  zone: "default"
 
-	v.SetEnvPrefix("LOCALMESH")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
+gateway:
+  host: "0.0.0.0"
+  port: 8080
+  hostname: "campus"
+
+grpc:
+  enabled: true
+  port: 9000
 
+log:
+  level: "info"
+  format: "text"
+`
+
+// Load reads configuration from file and environment
+func Load(configPath string) (*Config, error) {
+	v := newViper(configPath)
+
+	fileRead := true
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("reading config: %w", err)
 		}
+		fileRead = false
+	}
+
+	var warnings []string
+	if fileRead {
+		warnings = migrate(v)
 	}
 
 	var config Config
 	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
+	config.Warnings = warnings
 
 	if err := config.validate(); err != nil {
 		return nil, fmt.Errorf("validating config: %w", err)
@@ -186,6 +269,39 @@ func Load(configPath string) (*Config, error) {
 	return &config, nil
 }
 
+// newViper returns a viper instance with defaults, search paths and env bindings set
+func newViper(configPath string) *viper.Viper {
+	v := fileViper(configPath)
+	setDefaults(v)
+
+	v.SetEnvPrefix("LOCALMESH")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	return v
+}
+
+// fileViper returns a viper instance that only knows where to find the
+// config file, so its keys are exactly what the file contains
+func fileViper(configPath string) *viper.Viper {
+	v := viper.New()
+
+	if configPath != "" {
+		v.SetConfigFile(configPath)
+	} else {
+		v.SetConfigName("localmesh")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(".")
+		v.AddConfigPath("./configs")
+		v.AddConfigPath("/etc/localmesh")
+		if home, _ := os.UserHomeDir(); home != "" {
+			v.AddConfigPath(filepath.Join(home, ".config", "localmesh"))
+		}
+	}
+
+	return v
+}
+
 // Get returns the current configuration
 func Get() *Config {
 	cfgMu.RLock()
@@ -216,6 +332,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("security.refresh_token_ttl", "24h")
 	v.SetDefault("security.key_path", "./data/keys")
 	v.SetDefault("security.require_zone_auth", true)
+	v.SetDefault("security.rate_limit_enabled", false)
 	v.SetDefault("security.rate_limit", 100)
 	v.SetDefault("security.rate_limit_burst", 20)
 	v.SetDefault("security.rate_limit_window", "1m")
@@ -230,6 +347,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("gateway.idle_timeout", "120s")
 	v.SetDefault("gateway.max_body_size", 10485760)
 	v.SetDefault("gateway.cors_origins", []string{"*"})
+	v.SetDefault("gateway.metrics_enabled", false)
+	v.SetDefault("gateway.max_connections", 0)
+	v.SetDefault("gateway.proxy_retries", 2)
+	v.SetDefault("gateway.proxy_backoff", "100ms")
+	v.SetDefault("gateway.drain_timeout", "20s")
+	v.SetDefault("gateway.compression_enabled", false)
 
 	v.SetDefault("grpc.enabled", true)
 	v.SetDefault("grpc.host", "0.0.0.0")
@@ -244,6 +367,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("log.level", "info")
 	v.SetDefault("log.format", "text")
 	v.SetDefault("log.output", "stdout")
+	v.SetDefault("log.buffer_lines", 1000)
 }
 
 func (c *Config) validate() error {
@@ -275,10 +399,140 @@ func (c *Config) validate() error {
 	if c.GRPC.Enabled && (c.GRPC.Port < 1 || c.GRPC.Port > 65535) {
 		return fmt.Errorf("invalid grpc port: %d", c.GRPC.Port)
 	}
+	if c.Gateway.MaxConnections < 0 {
+		return fmt.Errorf("invalid gateway max_connections: %d", c.Gateway.MaxConnections)
+	}
+	if c.Gateway.ProxyRetries < 0 {
+		return fmt.Errorf("invalid gateway proxy_retries: %d", c.Gateway.ProxyRetries)
+	}
+	if c.Security.RateLimit < 0 || c.Security.RateLimitBurst < 0 {
+		return fmt.Errorf("invalid security rate_limit: %d (burst %d)", c.Security.RateLimit, c.Security.RateLimitBurst)
+	}
+	if c.Log.BufferLines < 0 {
+		return fmt.Errorf("invalid log buffer_lines: %d", c.Log.BufferLines)
+	}
+	for _, z := range c.Zones {
+		if z.RateLimit < 0 {
+			return fmt.Errorf("zone %q: invalid rate_limit: %d", z.ID, z.RateLimit)
+		}
+	}
+
+	if q := c.Network.QuietHours; q.Enabled() {
+		if _, _, err := q.Offsets(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// detectNodeIP finds this node's primary LAN address; tests replace it
+var detectNodeIP = func() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no suitable IP address found")
+}
+
+// ResolveServices returns the configured services with {{.NodeIP}} and
+// {{.Zone}} in their URLs resolved to this node's primary LAN address and
+// zone, and each URL normalized. Services whose URL is missing or can't be
+// resolved are left out and reported in errs, so one bad entry doesn't
+// keep the node from starting.
+func (c *Config) ResolveServices() ([]ServiceConfig, []error) {
+	var (
+		services []ServiceConfig
+		errs     []error
+		nodeIP   string
+		ipErr    error
+		detected bool
+	)
+
+	for _, svc := range c.Services {
+		if strings.Contains(svc.URL, "{{") {
+			needsIP := strings.Contains(svc.URL, ".NodeIP")
+			if needsIP && !detected {
+				nodeIP, ipErr = detectNodeIP()
+				detected = true
+			}
+			if needsIP && ipErr != nil {
+				errs = append(errs, fmt.Errorf("service %q url: detecting node IP: %w", svc.Name, ipErr))
+				continue
+			}
+
+			resolved, err := expandURL(svc.URL, nodeIP, c.Node.Zone)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("service %q url: %w", svc.Name, err))
+				continue
+			}
+			svc.URL = resolved
+		}
+
+		normalized, err := normalizeServiceURL(svc.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("service %q: %w", svc.Name, err))
+			continue
+		}
+		svc.URL = normalized
+		services = append(services, svc)
+	}
+
+	return services, errs
+}
+
+// expandURL executes a service URL template
+func expandURL(raw, nodeIP, zone string) (string, error) {
+	tmpl, err := template.New("url").Parse(raw)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		NodeIP string
+		Zone   string
+	}{nodeIP, zone}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// normalizeServiceURL defaults a missing scheme to http://, rejects schemes
+// other than http and https, and strips trailing slashes
+func normalizeServiceURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("url is required")
+	}
+
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", raw, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported url scheme %q (use http or https)", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("url %q has no host", raw)
+	}
+
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
 func expandPath(path string) string {
 	if strings.HasPrefix(path, "~/") {
 		if home, err := os.UserHomeDir(); err == nil {
@@ -288,6 +542,27 @@ func expandPath(path string) string {
 	return path
 }
 
+// defaultProxyPort is the .local reverse proxy port when none is configured;
+// high enough that no root privileges are needed
+const defaultProxyPort = 8081
+
+// ProxyPort returns the effective .local reverse proxy port
+func (c *Config) ProxyPort() int {
+	if c.Gateway.ProxyPort > 0 {
+		return c.Gateway.ProxyPort
+	}
+	return defaultProxyPort
+}
+
+// MDNSDomain returns the mDNS domain services are published under,
+// without its trailing dot
+func (c *Config) MDNSDomain() string {
+	if d := strings.TrimSuffix(c.Network.Domain, "."); d != "" {
+		return d
+	}
+	return "local"
+}
+
 // GatewayAddr returns the gateway listen address
 func (c *Config) GatewayAddr() string {
 	return fmt.Sprintf("%s:%d", c.Gateway.Host, c.Gateway.Port)
@@ -303,6 +578,11 @@ func (c *Config) NetworkAddr() string {
 	return fmt.Sprintf(":%d", c.Network.Port)
 }
 
+// PIDFile returns the path of the running server's PID file
+func (c *Config) PIDFile() string {
+	return filepath.Join(c.Storage.DataDir, "localmesh.pid")
+}
+
 // Save writes the configuration to a file
 func Save(configPath string, c *Config) error {
 	if configPath == "" {
@@ -314,6 +594,7 @@ func Save(configPath string, c *Config) error {
 	v.SetConfigType("yaml")
 
 	// Set all config values
+	v.Set("config_version", SchemaVersion)
 	v.Set("node", c.Node)
 	v.Set("network", c.Network)
 	v.Set("storage", c.Storage)
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func stubNodeIP(t *testing.T, ip string, err error) {
+	t.Helper()
+	orig := detectNodeIP
+	detectNodeIP = func() (string, error) { return ip, err }
+	t.Cleanup(func() { detectNodeIP = orig })
+}
+
+func TestResolveServicesExpandsNodeIP(t *testing.T) {
+	stubNodeIP(t, "10.20.0.5", nil)
+
+	cfg := &Config{
+		Node: NodeConfig{Zone: "library"},
+		Services: []ServiceConfig{
+			{Name: "wiki", URL: "http://{{.NodeIP}}:3000"},
+			{Name: "map", URL: "http://{{.Zone}}-map.lan"},
+		},
+	}
+
+	services, errs := cfg.ResolveServices()
+	if len(errs) > 0 {
+		t.Fatalf("ResolveServices errors: %v", errs)
+	}
+	if got, want := services[0].URL, "http://10.20.0.5:3000"; got != want {
+		t.Errorf("wiki url = %q, want %q", got, want)
+	}
+	if got, want := services[1].URL, "http://library-map.lan"; got != want {
+		t.Errorf("map url = %q, want %q", got, want)
+	}
+}
+
+func TestResolveServicesSkipsUnresolvable(t *testing.T) {
+	stubNodeIP(t, "", errors.New("no suitable IP address found"))
+
+	cfg := &Config{Services: []ServiceConfig{
+		{Name: "wiki", URL: "http://{{.NodeIP}}:3000"},
+		{Name: "docs", URL: "http://docs.lan"},
+	}}
+
+	services, errs := cfg.ResolveServices()
+	if len(errs) != 1 {
+		t.Errorf("got %d errors, want 1: %v", len(errs), errs)
+	}
+	if len(services) != 1 || services[0].Name != "docs" {
+		t.Errorf("services = %+v, want only docs", services)
+	}
+}
+
+func TestLoadDoesNotDetectNodeIP(t *testing.T) {
+	stubNodeIP(t, "", errors.New("detection must not run during Load"))
+	path := writeConfig(t, "services:\n  - name: wiki\n    url: http://{{.NodeIP}}:3000\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Services[0].URL; got != "http://{{.NodeIP}}:3000" {
+		t.Errorf("url = %q, want template left unresolved", got)
+	}
+}
+
+func TestNormalizeServiceURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "localhost:3000", want: "http://localhost:3000"},
+		{raw: "10.0.0.4:8080/app/", want: "http://10.0.0.4:8080/app"},
+		{raw: "https://wiki.campus.lan/", want: "https://wiki.campus.lan"},
+		{raw: "HTTPS://wiki.campus.lan", want: "https://wiki.campus.lan"},
+		{raw: "ftp://files.campus.lan", wantErr: true},
+		{raw: "http://", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeServiceURL(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("normalizeServiceURL(%q) = %q, want error", tt.raw, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeServiceURL(%q) = %q, %v; want %q", tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadAcceptsServiceWithoutURL(t *testing.T) {
+	path := writeConfig(t, "services:\n  - name: wiki\n  - name: ftp\n    url: ftp://files.lan\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	services, errs := cfg.ResolveServices()
+	if len(services) != 0 || len(errs) != 2 {
+		t.Errorf("ResolveServices = %+v, %v; want both entries skipped", services, errs)
+	}
+}
+
+func TestCompressionDisabledByDefault(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "node:\n  name: library-1\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Gateway.CompressionEnabled {
+		t.Error("gateway.compression_enabled defaults to true, want opt-in")
+	}
+}
+
+func TestQuietHoursOffsets(t *testing.T) {
+	start, end, err := QuietHours{Start: "22:00", End: "06:30"}.Offsets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 22*time.Hour || end != 6*time.Hour+30*time.Minute {
+		t.Errorf("Offsets = %v, %v", start, end)
+	}
+
+	for _, q := range []QuietHours{{Start: "25:00", End: "06:00"}, {Start: "22:00"}, {Start: "08:00", End: "08:00"}} {
+		if _, _, err := q.Offsets(); err == nil {
+			t.Errorf("Offsets(%+v) accepted", q)
+		}
+	}
+}
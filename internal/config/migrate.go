@@ -0,0 +1,258 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// SchemaVersion is the current localmesh.yaml schema version.
+// Bump it whenever renamedKeys or removedKeys change.
+const SchemaVersion = 1
+
+// renamedKeys maps deprecated config keys to their current names
+var renamedKeys = map[string]string{}
+
+// removedKeys maps config keys that are no longer read to a short reason
+var removedKeys = map[string]string{}
+
+// migrate maps deprecated keys onto their replacements in v and returns a
+// warning for every deprecated, removed or unversioned setting it found.
+// A value already set under the new key always wins over the old one.
+func migrate(v *viper.Viper) []string {
+	var warnings []string
+
+	if version := v.GetInt("config_version"); version < SchemaVersion {
+		warnings = append(warnings, fmt.Sprintf("config schema version %d is older than %d, run 'localmesh start --migrate' to upgrade", version, SchemaVersion))
+	}
+
+	for _, oldKey := range sortedKeys(renamedKeys) {
+		if !v.InConfig(oldKey) {
+			continue
+		}
+		newKey := renamedKeys[oldKey]
+		if !v.InConfig(newKey) {
+			v.Set(newKey, v.Get(oldKey))
+		}
+		warnings = append(warnings, fmt.Sprintf("config key %q is deprecated, use %q", oldKey, newKey))
+	}
+
+	for _, key := range sortedKeys(removedKeys) {
+		if v.InConfig(key) {
+			warnings = append(warnings, fmt.Sprintf("config key %q is no longer supported (%s) and is ignored", key, removedKeys[key]))
+		}
+	}
+
+	return warnings
+}
+
+// Migrate upgrades a config file in place to the current schema version.
+// Deprecated keys are rewritten under their new names and removed keys are
+// dropped. Only settings present in the file are written back: defaults and
+// LOCALMESH_* environment overrides are never baked in. YAML files are
+// edited node by node so comments and key order survive. It returns the
+// path written and the migration warnings.
+func Migrate(configPath string) (string, []string, error) {
+	v := fileViper(configPath)
+	if err := v.ReadInConfig(); err != nil {
+		return "", nil, fmt.Errorf("reading config: %w", err)
+	}
+	path := v.ConfigFileUsed()
+
+	warnings := migrate(v)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("reading config: %w", err)
+		}
+		if data, err = migrateYAML(data); err != nil {
+			return "", nil, fmt.Errorf("migrating config: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return "", nil, fmt.Errorf("writing config: %w", err)
+		}
+	default:
+		out := viper.New()
+		for _, key := range v.AllKeys() {
+			if !v.InConfig(key) {
+				continue
+			}
+			if _, ok := renamedKeys[key]; ok {
+				continue
+			}
+			if _, ok := removedKeys[key]; ok {
+				continue
+			}
+			out.Set(key, v.Get(key))
+		}
+		out.Set("config_version", SchemaVersion)
+
+		if err := out.WriteConfigAs(path); err != nil {
+			return "", nil, fmt.Errorf("writing config: %w", err)
+		}
+	}
+
+	return path, warnings, nil
+}
+
+// migrateYAML applies renamedKeys and removedKeys to a YAML document and
+// stamps config_version, leaving everything else (comments included) as is
+func migrateYAML(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level of config is not a mapping")
+	}
+
+	for _, oldKey := range sortedKeys(renamedKeys) {
+		key, _ := findYAMLKey(root, oldKey)
+		if key == nil {
+			continue
+		}
+		newKey := renamedKeys[oldKey]
+		if existing, _ := findYAMLKey(root, newKey); existing != nil {
+			removeYAMLKey(root, oldKey) // the new key wins, as in migrate
+			continue
+		}
+
+		oldParent, _ := splitKey(oldKey)
+		newParent, leaf := splitKey(newKey)
+		if oldParent == newParent {
+			key.Value = leaf // rename in place, keeping position and comments
+			continue
+		}
+		key, value := removeYAMLKey(root, oldKey)
+		key.Value = leaf
+		parent := ensureYAMLMapping(root, newParent)
+		parent.Content = append(parent.Content, key, value)
+	}
+
+	for _, k := range sortedKeys(removedKeys) {
+		removeYAMLKey(root, k)
+	}
+
+	version := strconv.Itoa(SchemaVersion)
+	if _, value := findYAMLKey(root, "config_version"); value != nil {
+		value.Kind, value.Tag, value.Value = yaml.ScalarNode, "!!int", version
+	} else {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "config_version"}
+		if len(root.Content) > 0 {
+			// Keep a leading file comment above the new first key
+			key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+		}
+		root.Content = append([]*yaml.Node{key, {Kind: yaml.ScalarNode, Tag: "!!int", Value: version}}, root.Content...)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// findYAMLKey looks up a dotted key (matched case-insensitively, like
+// viper) and returns its key and value nodes, or nils if absent
+func findYAMLKey(root *yaml.Node, dotted string) (*yaml.Node, *yaml.Node) {
+	node := root
+	parts := strings.Split(dotted, ".")
+	for i, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if strings.EqualFold(node.Content[j].Value, part) {
+				if i == len(parts)-1 {
+					return node.Content[j], node.Content[j+1]
+				}
+				next = node.Content[j+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil, nil
+		}
+		node = next
+	}
+	return nil, nil
+}
+
+// removeYAMLKey deletes a dotted key and returns the removed nodes
+func removeYAMLKey(root *yaml.Node, dotted string) (*yaml.Node, *yaml.Node) {
+	path, leaf := splitKey(dotted)
+	parent := root
+	if path != "" {
+		if _, parent = findYAMLKey(root, path); parent == nil || parent.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+	}
+	for j := 0; j+1 < len(parent.Content); j += 2 {
+		if strings.EqualFold(parent.Content[j].Value, leaf) {
+			key, value := parent.Content[j], parent.Content[j+1]
+			parent.Content = append(parent.Content[:j], parent.Content[j+2:]...)
+			return key, value
+		}
+	}
+	return nil, nil
+}
+
+// ensureYAMLMapping returns the mapping at a dotted path, creating empty
+// mappings as needed ("" is the root)
+func ensureYAMLMapping(root *yaml.Node, dotted string) *yaml.Node {
+	node := root
+	if dotted == "" {
+		return node
+	}
+	for _, part := range strings.Split(dotted, ".") {
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if strings.EqualFold(node.Content[j].Value, part) && node.Content[j+1].Kind == yaml.MappingNode {
+				next = node.Content[j+1]
+				break
+			}
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, next)
+		}
+		node = next
+	}
+	return node
+}
+
+// splitKey splits "gateway.hostname" into "gateway" and "hostname"
+func splitKey(dotted string) (string, string) {
+	if i := strings.LastIndex(dotted, "."); i != -1 {
+		return dotted[:i], dotted[i+1:]
+	}
+	return "", dotted
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
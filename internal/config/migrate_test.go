@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir) // Load creates the default ./data directories
+	path := filepath.Join(dir, "localmesh.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// withKeyChanges swaps in test-only renamed and removed key tables
+func withKeyChanges(t *testing.T, renamed, removed map[string]string) {
+	t.Helper()
+	origRenamed, origRemoved := renamedKeys, removedKeys
+	renamedKeys, removedKeys = renamed, removed
+	t.Cleanup(func() { renamedKeys, removedKeys = origRenamed, origRemoved })
+}
+
+func TestLoadMapsDeprecatedKeys(t *testing.T) {
+	withKeyChanges(t, map[string]string{"gateway.domain": "gateway.hostname"}, nil)
+	path := writeConfig(t, "gateway:\n  domain: library\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.Gateway.Hostname != "library" {
+		t.Errorf("gateway.hostname = %q, want %q", cfg.Gateway.Hostname, "library")
+	}
+
+	var deprecated bool
+	for _, w := range cfg.Warnings {
+		if strings.Contains(w, `"gateway.domain" is deprecated`) {
+			deprecated = true
+		}
+	}
+	if !deprecated {
+		t.Errorf("no deprecation warning in %q", cfg.Warnings)
+	}
+}
+
+func TestMigrateRewritesOnlyFileKeys(t *testing.T) {
+	withKeyChanges(t, map[string]string{"gateway.domain": "gateway.hostname"}, map[string]string{"gateway.legacy": "unused"})
+	t.Setenv("LOCALMESH_LOG_LEVEL", "debug")
+	path := writeConfig(t, `# campus node
+node:
+  name: library-1 # front desk
+gateway:
+  domain: library
+  legacy: true
+  port: 9090
+`)
+
+	if _, warnings, err := Migrate(path); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	} else if len(warnings) == 0 {
+		t.Error("expected migration warnings")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+
+	for _, want := range []string{"config_version: 1", "# campus node", "# front desk", "hostname: library", "port: 9090"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("migrated config missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"domain:", "legacy:", "storage:", "security:", "debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("migrated config contains %q:\n%s", unwanted, out)
+		}
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after migrate: %v", err)
+	}
+	if cfg.Gateway.Hostname != "library" || len(cfg.Warnings) != 0 {
+		t.Errorf("after migrate: hostname %q, warnings %q", cfg.Gateway.Hostname, cfg.Warnings)
+	}
+}
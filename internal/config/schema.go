@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaDraft is the JSON Schema dialect Schema produces
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Schema returns a JSON Schema for localmesh.yaml, generated from the
+// mapstructure tags on Config so it can't drift from what Load accepts
+func Schema() map[string]any {
+	schema := schemaFor(reflect.TypeOf(Config{}))
+
+	// Deprecated keys still load (with a warning), so they must validate
+	for _, oldKey := range sortedKeys(renamedKeys) {
+		newKey := renamedKeys[oldKey]
+		prop := map[string]any{"deprecated": true, "description": fmt.Sprintf("Deprecated: use %s", newKey)}
+		if current := schemaProperty(schema, newKey); current != nil {
+			for k, v := range current {
+				if k != "description" {
+					prop[k] = v
+				}
+			}
+		}
+		setSchemaProperty(schema, oldKey, prop)
+	}
+	for _, key := range sortedKeys(removedKeys) {
+		setSchemaProperty(schema, key, map[string]any{
+			"deprecated":  true,
+			"description": fmt.Sprintf("No longer supported (%s); ignored", removedKeys[key]),
+		})
+	}
+
+	schema["$schema"] = schemaDraft
+	schema["title"] = "LocalMesh configuration"
+	return schema
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	if t == durationType {
+		// Viper decodes strings like "30s" and plain nanosecond counts
+		return map[string]any{
+			"type":        []string{"string", "integer"},
+			"pattern":     `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`,
+			"description": "Go duration, e.g. 30s or 1h30m",
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key := f.Tag.Get("mapstructure")
+			if key == "" || key == "-" || !f.IsExported() {
+				continue
+			}
+			props[key] = schemaFor(f.Type)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	}
+	return map[string]any{}
+}
+
+// schemaProperty returns the schema for a dotted key, or nil if there is none
+func schemaProperty(schema map[string]any, dotted string) map[string]any {
+	for _, part := range strings.Split(dotted, ".") {
+		props, _ := schema["properties"].(map[string]any)
+		if schema, _ = props[part].(map[string]any); schema == nil {
+			return nil
+		}
+	}
+	return schema
+}
+
+// setSchemaProperty adds prop under a dotted key whose parent object exists
+func setSchemaProperty(schema map[string]any, dotted string, prop map[string]any) {
+	parent, key := splitKey(dotted)
+	if parent != "" {
+		schema = schemaProperty(schema, parent)
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		props[key] = prop
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// validate checks doc against the subset of JSON Schema that Schema emits
+func validate(schema map[string]any, v any, path string) []string {
+	var errs []string
+
+	if types, ok := schema["type"]; ok && !matchesType(types, v) {
+		return []string{fmt.Sprintf("%s: %T does not match type %v", path, v, types)}
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if s, isString := v.(string); isString && !regexp.MustCompile(pattern).MatchString(s) {
+			errs = append(errs, fmt.Sprintf("%s: %q does not match %s", path, s, pattern))
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for key, val := range v {
+			if sub, ok := props[key].(map[string]any); ok {
+				errs = append(errs, validate(sub, val, path+"."+key)...)
+			} else if sub, ok := schema["additionalProperties"].(map[string]any); ok {
+				errs = append(errs, validate(sub, val, path+"."+key)...)
+			} else if schema["additionalProperties"] == false {
+				errs = append(errs, fmt.Sprintf("%s: unknown key %q", path, key))
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, val := range v {
+				errs = append(errs, validate(items, val, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+func matchesType(types any, v any) bool {
+	list, ok := types.([]any)
+	if !ok {
+		list = []any{types}
+	}
+	for _, t := range list {
+		switch t {
+		case "object":
+			_, ok = v.(map[string]any)
+		case "array":
+			_, ok = v.([]any)
+		case "string":
+			_, ok = v.(string)
+		case "boolean":
+			_, ok = v.(bool)
+		case "integer":
+			_, ok = v.(int)
+		case "number":
+			switch v.(type) {
+			case int, float64:
+				ok = true
+			default:
+				ok = false
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSchema round-trips Schema through JSON, as editors consume it
+func loadSchema(t *testing.T) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func validateYAML(t *testing.T, schema map[string]any, doc string) []string {
+	t.Helper()
+	var v any
+	if err := yaml.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+	return validate(schema, v, "$")
+}
+
+func TestSchemaAcceptsDefaultConfig(t *testing.T) {
+	if errs := validateYAML(t, loadSchema(t), DefaultYAML); len(errs) > 0 {
+		t.Errorf("init config rejected: %q", errs)
+	}
+}
+
+func TestSchemaRejectsBadPort(t *testing.T) {
+	if errs := validateYAML(t, loadSchema(t), "gateway:\n  port: \"eighty\"\n"); len(errs) == 0 {
+		t.Error("string gateway.port accepted")
+	}
+	if errs := validateYAML(t, loadSchema(t), "gateway:\n  prot: 8080\n"); len(errs) == 0 {
+		t.Error("unknown key gateway.prot accepted")
+	}
+}
+
+func TestSchemaAcceptsDeprecatedKeys(t *testing.T) {
+	if prop := schemaProperty(loadSchema(t), "gateway.domain"); prop != nil {
+		t.Fatalf("schema lists gateway.domain without a rename: %v", prop)
+	}
+
+	withKeyChanges(t, map[string]string{"gateway.domain": "gateway.hostname"}, map[string]string{"gateway.legacy": "unused"})
+	schema := loadSchema(t)
+	if errs := validateYAML(t, schema, "gateway:\n  domain: campus\n  legacy: true\n"); len(errs) > 0 {
+		t.Errorf("deprecated keys rejected: %q", errs)
+	}
+	for _, key := range []string{"gateway.domain", "gateway.legacy"} {
+		if prop := schemaProperty(schema, key); prop["deprecated"] != true {
+			t.Errorf("%s not marked deprecated: %v", key, prop)
+		}
+	}
+	if errs := validateYAML(t, schema, "gateway:\n  domain: 5\n"); len(errs) == 0 {
+		t.Error("renamed key does not take its replacement's type")
+	}
+}
+
+func TestSchemaDurations(t *testing.T) {
+	schema := loadSchema(t)
+	for _, d := range []string{"30s", "1h30m", "0", "-1s", "1.5h", "+2m", "300ms"} {
+		if errs := validateYAML(t, schema, "gateway:\n  drain_timeout: "+d+"\n"); len(errs) > 0 {
+			t.Errorf("duration %q rejected: %q", d, errs)
+		}
+	}
+	for _, d := range []string{"30", "1 h", "s", "1d"} {
+		if errs := validateYAML(t, schema, "gateway:\n  drain_timeout: \""+d+"\"\n"); len(errs) == 0 {
+			t.Errorf("duration %q accepted", d)
+		}
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/FABLOUSFALCON/localmesh/internal/config"
+	"github.com/FABLOUSFALCON/localmesh/internal/events"
 	"github.com/FABLOUSFALCON/localmesh/internal/gateway"
 )
 
@@ -21,6 +24,8 @@ import (
 type Framework struct {
 	config  *config.Config
 	gateway *gateway.Gateway
+	events  *events.Bus
+	logs    *LogBuffer // nil when log.buffer_lines is 0
 	logger  *slog.Logger
 
 	mu      sync.RWMutex
@@ -33,15 +38,7 @@ type Framework struct {
 
 // New creates a new LocalMesh framework instance
 func New(cfg *config.Config) (*Framework, error) {
-	logLevel := slog.LevelInfo
-	switch cfg.Log.Level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	}
+	logLevel := cfg.Log.SlogLevel()
 
 	var handler slog.Handler
 	if cfg.Log.Format == "json" {
@@ -49,8 +46,19 @@ func New(cfg *config.Config) (*Framework, error) {
 	} else {
 		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel})
 	}
+
+	bus := events.NewBus()
+	var logs *LogBuffer
+	if cfg.Log.BufferLines > 0 {
+		logs = NewLogBuffer(cfg.Log.BufferLines, bus)
+		handler = logs.Handler(handler)
+	}
 	logger := slog.New(handler)
 
+	for _, w := range cfg.Warnings {
+		logger.Warn(w)
+	}
+
 	nodeID := cfg.Node.ID
 	if nodeID == "" {
 		nodeID = uuid.New().String()
@@ -60,6 +68,8 @@ func New(cfg *config.Config) (*Framework, error) {
 
 	return &Framework{
 		config: cfg,
+		events: bus,
+		logs:   logs,
 		logger: logger,
 		nodeID: nodeID,
 		ctx:    ctx,
@@ -82,18 +92,49 @@ func (f *Framework) Start() error {
 	cfg := gateway.DefaultGatewayConfig()
 	cfg.Host = f.config.Gateway.Host
 	cfg.Port = f.config.Gateway.Port
-	if f.config.Gateway.ProxyPort > 0 {
-		cfg.ProxyPort = f.config.Gateway.ProxyPort
-	}
+	cfg.ProxyPort = f.config.ProxyPort()
+	cfg.Domain = f.config.MDNSDomain()
 	cfg.ReadTimeout = f.config.Gateway.ReadTimeout
 	cfg.WriteTimeout = f.config.Gateway.WriteTimeout
+	cfg.RequireNetwork = f.config.Network.Required
+	cfg.MetricsEnabled = f.config.Gateway.MetricsEnabled
+	cfg.MaxConnections = f.config.Gateway.MaxConnections
+	cfg.ProxyRetries = f.config.Gateway.ProxyRetries
+	cfg.ProxyBackoff = f.config.Gateway.ProxyBackoff
+	cfg.EnableCompression = f.config.Gateway.CompressionEnabled
+	cfg.Events = f.events
 	cfg.Logger = f.logger
 
+	if q := f.config.Network.QuietHours; q.Enabled() {
+		start, end, err := q.Offsets()
+		if err != nil {
+			return fmt.Errorf("configuring quiet hours: %w", err)
+		}
+		cfg.QuietHours = &gateway.QuietWindow{Start: start, End: end}
+	}
+
+	if f.config.Security.RateLimitEnabled {
+		limits, err := f.rateLimitConfig()
+		if err != nil {
+			return fmt.Errorf("configuring rate limits: %w", err)
+		}
+		cfg.RateLimit = limits
+	}
+
 	f.gateway = gateway.NewGateway(cfg)
+	if f.logs != nil {
+		f.gateway.Mux().Handle("GET /api/v1/logs", f.logs)
+	}
+
+	if err := writePIDFile(f.config.PIDFile()); err != nil {
+		return err
+	}
 
 	if err := f.gateway.Start(); err != nil {
+		os.Remove(f.config.PIDFile())
 		return fmt.Errorf("starting gateway: %w", err)
 	}
+	f.registerServices()
 
 	f.mu.Lock()
 	f.running = true
@@ -103,6 +144,21 @@ func (f *Framework) Start() error {
 	return nil
 }
 
+// registerServices advertises the services from localmesh.yaml through the
+// gateway. Services whose URL can't be resolved are skipped with a warning.
+func (f *Framework) registerServices() {
+	services, errs := f.config.ResolveServices()
+	for _, err := range errs {
+		f.logger.Warn("skipping configured service", "error", err)
+	}
+
+	for _, svc := range services {
+		if err := f.gateway.AdvertiseUpstream(svc.Name, svc.URL, svc.Description, svc.Tags); err != nil {
+			f.logger.Warn("failed to register configured service", "name", svc.Name, "error", err)
+		}
+	}
+}
+
 // Stop gracefully shuts down all components
 func (f *Framework) Stop() error {
 	f.mu.Lock()
@@ -120,11 +176,21 @@ func (f *Framework) Stop() error {
 	defer cancel()
 
 	if f.gateway != nil {
+		drainCtx, cancelDrain := context.WithTimeout(ctx, f.config.Gateway.DrainTimeout)
+		if err := f.gateway.Drain(drainCtx); err != nil {
+			f.logger.Warn("proxy requests still in flight after drain timeout", "timeout", f.config.Gateway.DrainTimeout)
+		}
+		cancelDrain()
+
 		if err := f.gateway.Stop(ctx); err != nil {
 			f.logger.Warn("error stopping gateway", "error", err)
 		}
 	}
 
+	if err := os.Remove(f.config.PIDFile()); err != nil && !os.IsNotExist(err) {
+		f.logger.Warn("error removing pid file", "error", err)
+	}
+
 	f.logger.Info("LocalMesh stopped")
 	return nil
 }
@@ -147,9 +213,47 @@ func (f *Framework) NodeID() string {
 	return f.nodeID
 }
 
+// Events returns the bus components publish events to
+func (f *Framework) Events() *events.Bus {
+	return f.events
+}
+
 // IsRunning returns true if the framework is running
 func (f *Framework) IsRunning() bool {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 	return f.running
 }
+
+// rateLimitConfig builds gateway rate limits from the security settings,
+// with per-zone overrides matched against zone subnets by priority.
+func (f *Framework) rateLimitConfig() (*gateway.RateLimitConfig, error) {
+	sec := f.config.Security
+	limits := &gateway.RateLimitConfig{
+		Limit:  sec.RateLimit,
+		Burst:  sec.RateLimitBurst,
+		Window: sec.RateLimitWindow,
+	}
+
+	zones := slices.Clone(f.config.Zones)
+	slices.SortStableFunc(zones, func(a, b config.ZoneConfig) int {
+		return b.Priority - a.Priority
+	})
+
+	for _, z := range zones {
+		if z.RateLimit == 0 {
+			continue
+		}
+		zl := gateway.ZoneLimit{Zone: z.ID, Limit: z.RateLimit}
+		for _, cidr := range z.Subnets {
+			_, subnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("zone %q: %w", z.ID, err)
+			}
+			zl.Subnets = append(zl.Subnets, subnet)
+		}
+		limits.Zones = append(limits.Zones, zl)
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/localmesh/internal/config"
+	"github.com/FABLOUSFALCON/localmesh/internal/gateway"
+)
+
+// fakeAvahi puts stand-in avahi publishers on PATH that run until killed
+func fakeAvahi(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"avahi-publish-address", "avahi-publish-service"} {
+		script := "#!/bin/sh\nexec sleep 60\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+// newTestFramework returns a framework whose gateway is created but not
+// started, advertising services at 10.0.0.2
+func newTestFramework(t *testing.T, cfg *config.Config) *Framework {
+	t.Helper()
+	fakeAvahi(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	f := &Framework{config: cfg, logger: logger}
+	f.gateway = gateway.NewGateway(gateway.GatewayConfig{
+		ProxyPort: 8081,
+		DetectIP:  func() (string, error) { return "10.0.0.2", nil },
+		Logger:    logger,
+	})
+	t.Cleanup(func() {
+		for _, svc := range cfg.Services {
+			f.gateway.StopAdvertisingService(svc.Name)
+		}
+	})
+	return f
+}
+
+func getService(t *testing.T, f *Framework, name string) (int, gateway.MDNSService) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	f.gateway.Mux().ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/services/"+name, nil))
+	var svc gateway.MDNSService
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Code, svc
+}
+
+func TestRegisterServicesResolvesTemplates(t *testing.T) {
+	f := newTestFramework(t, &config.Config{
+		Node: config.NodeConfig{Zone: "library"},
+		Services: []config.ServiceConfig{
+			{Name: "catalog", URL: "http://{{.Zone}}-catalog.campus.lan:3000/", Description: "Book search"},
+			{Name: "broken", URL: "http://{{.Nope}}:3000"},
+		},
+	})
+
+	f.registerServices()
+
+	code, svc := getService(t, f, "catalog")
+	if code != http.StatusOK {
+		t.Fatalf("catalog not registered: %d", code)
+	}
+	if svc.Upstream != "http://library-catalog.campus.lan:3000" {
+		t.Errorf("upstream = %q", svc.Upstream)
+	}
+	if svc.IP != "10.0.0.2" || svc.Port != 3000 || svc.Description != "Book search" {
+		t.Errorf("advertised %s port %d description %q", svc.IP, svc.Port, svc.Description)
+	}
+
+	if code, _ := getService(t, f, "broken"); code != http.StatusNotFound {
+		t.Errorf("unresolvable service registered: %d", code)
+	}
+}
+
+func TestRegisterServicesProxiesNormalizedURL(t *testing.T) {
+	var gotPath, gotHost string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotHost = r.URL.Path, r.Host
+	}))
+	defer backend.Close()
+	hostPort := strings.TrimPrefix(backend.URL, "http://")
+
+	// No scheme and a trailing slash, as users tend to write it
+	f := newTestFramework(t, &config.Config{
+		Services: []config.ServiceConfig{{Name: "wiki", URL: " " + hostPort + "/docs/ "}},
+	})
+	f.registerServices()
+
+	if _, svc := getService(t, f, "wiki"); svc.Upstream != "http://"+hostPort+"/docs" {
+		t.Errorf("upstream = %q, want %q", svc.Upstream, "http://"+hostPort+"/docs")
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/page", nil)
+	req.Host = "wiki.local"
+	f.gateway.ProxyHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("proxied request = %d: %s", rec.Code, rec.Body)
+	}
+	if gotPath != "/docs/page" || gotHost != hostPort {
+		t.Errorf("backend saw %s%s, want %s/docs/page", gotHost, gotPath, hostPort)
+	}
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestStartWithoutMDNS(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // No avahi, as on a laptop off the network
+
+	for _, required := range []bool{false, true} {
+		cfg := &config.Config{
+			Node:    config.NodeConfig{Zone: "default"},
+			Network: config.NetworkConfig{Required: required},
+			Storage: config.StorageConfig{DataDir: t.TempDir()},
+			Gateway: config.GatewayConfig{Host: "127.0.0.1", Port: freePort(t), ProxyPort: freePort(t), DrainTimeout: time.Second},
+			Log:     config.LogConfig{Level: "warn", BufferLines: 100},
+		}
+		f, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = f.Start()
+		if required {
+			if err == nil {
+				f.Stop()
+				t.Error("Start succeeded without mDNS when the network is required")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("best-effort Start: %v", err)
+		}
+
+		warned := false
+		for _, e := range f.logs.Entries(LogFilter{Level: slog.LevelWarn}) {
+			warned = warned || strings.Contains(e.Message, "serving locally only")
+		}
+		if !warned {
+			t.Error("no warning logged for the failed mDNS advertisement")
+		}
+		f.Stop()
+	}
+}
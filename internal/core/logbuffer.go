@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FABLOUSFALCON/localmesh/internal/events"
+)
+
+// LogEntry is a captured log record
+type LogEntry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Source  string         `json:"source,omitempty"` // Package that logged the record, e.g. "gateway"
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// LogFilter selects entries from a LogBuffer
+type LogFilter struct {
+	Since  time.Time  // Only entries after this time (zero = all)
+	Level  slog.Level // Minimum level
+	Source string     // Only entries from this source ("" = all)
+}
+
+// LogBuffer keeps the most recent log records in memory so they can be
+// served over the API and replayed by clients that connect later
+type LogBuffer struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	next    int
+	full    bool
+	events  *events.Bus
+}
+
+// NewLogBuffer creates a buffer holding up to size entries. When bus is
+// non-nil every captured entry is also published as an events.LogLine.
+func NewLogBuffer(size int, bus *events.Bus) *LogBuffer {
+	return &LogBuffer{
+		entries: make([]LogEntry, size),
+		events:  bus,
+	}
+}
+
+func (b *LogBuffer) add(e LogEntry) {
+	b.mu.Lock()
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+	b.mu.Unlock()
+
+	if b.events != nil {
+		b.events.Publish(events.LogLine, e)
+	}
+}
+
+// Entries returns the buffered entries matching f, oldest first
+func (b *LogBuffer) Entries(f LogFilter) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ordered := b.entries[:b.next]
+	if b.full {
+		ordered = append(append([]LogEntry{}, b.entries[b.next:]...), b.entries[:b.next]...)
+	}
+
+	matched := []LogEntry{}
+	for _, e := range ordered {
+		var level slog.Level
+		level.UnmarshalText([]byte(e.Level))
+		if level < f.Level || !e.Time.After(f.Since) {
+			continue
+		}
+		if f.Source != "" && e.Source != f.Source {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	return matched
+}
+
+// ServeHTTP serves GET /api/v1/logs?since=&level=&source=, where since is
+// an RFC 3339 timestamp and level the minimum level to return
+func (b *LogBuffer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := LogFilter{Level: slog.LevelDebug, Source: q.Get("source")}
+
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "since must be an RFC 3339 timestamp"})
+			return
+		}
+		f.Since = t
+	}
+	if level := q.Get("level"); level != "" {
+		if err := f.Level.UnmarshalText([]byte(level)); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "level must be debug, info, warn or error"})
+			return
+		}
+	}
+
+	entries := b.Entries(f)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// Handler wraps next so every record it handles is also captured
+func (b *LogBuffer) Handler(next slog.Handler) slog.Handler {
+	return &logHandler{next: next, buf: b}
+}
+
+// logHandler tees records into a LogBuffer
+type logHandler struct {
+	next   slog.Handler
+	buf    *LogBuffer
+	attrs  []slog.Attr // From WithAttrs, keys already group-qualified
+	prefix string      // Group prefix from WithGroup, e.g. "http."
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.next.Handle(ctx, r)
+
+	entry := LogEntry{
+		Time:    r.Time,
+		Level:   r.Level.String(),
+		Message: r.Message,
+		Source:  recordSource(r.PC),
+	}
+
+	if len(h.attrs) > 0 || r.NumAttrs() > 0 {
+		entry.Attrs = make(map[string]any)
+		for _, a := range h.attrs {
+			addAttr(entry.Attrs, "", a)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			addAttr(entry.Attrs, h.prefix, a)
+			return true
+		})
+	}
+
+	h.buf.add(entry)
+	return err
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	qualified := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		qualified[i] = slog.Attr{Key: h.prefix + a.Key, Value: a.Value}
+	}
+	return &logHandler{
+		next:   h.next.WithAttrs(attrs),
+		buf:    h.buf,
+		attrs:  append(append([]slog.Attr{}, h.attrs...), qualified...),
+		prefix: h.prefix,
+	}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{
+		next:   h.next.WithGroup(name),
+		buf:    h.buf,
+		attrs:  h.attrs,
+		prefix: h.prefix + name + ".",
+	}
+}
+
+// addAttr flattens a into m using dotted keys for groups
+func addAttr(m map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addAttr(m, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+
+	val := v.Any()
+	if err, ok := val.(error); ok {
+		val = err.Error()
+	}
+	m[prefix+a.Key] = val
+}
+
+// recordSource names the package that logged a record, e.g. "gateway"
+func recordSource(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	fn := path.Base(frame.Function) // e.g. "gateway.(*Gateway).Start"
+	if i := strings.Index(fn, "."); i != -1 {
+		return fn[:i]
+	}
+	return fn
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrNotRunning is returned when no live LocalMesh process owns the PID file
+var ErrNotRunning = errors.New("LocalMesh is not running")
+
+// ReadPID returns the PID recorded in path if that process is still alive.
+// A PID file left behind by a dead process is removed and ErrNotRunning
+// is returned, as it is when the file does not exist.
+func ReadPID(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, ErrNotRunning
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading pid file: %w", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		os.Remove(path)
+		return 0, ErrNotRunning
+	}
+
+	if !ProcessAlive(pid) {
+		os.Remove(path)
+		return 0, ErrNotRunning
+	}
+
+	return pid, nil
+}
+
+// ProcessAlive reports whether a process with the given PID exists
+func ProcessAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// writePIDFile records this process's PID, refusing if another live
+// process already owns the file
+func writePIDFile(path string) error {
+	if pid, err := ReadPID(path); err == nil && pid != os.Getpid() {
+		return fmt.Errorf("LocalMesh already running (pid %d)", pid)
+	}
+
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600)
+}
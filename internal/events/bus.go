@@ -0,0 +1,106 @@
+// Package events is an in-process publish/subscribe bus for LocalMesh
+// components. It lives outside core so the gateway can publish to it
+// without importing the framework.
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types published by LocalMesh components
+const (
+	ServiceRegistered   = "service.registered"
+	ServiceUnregistered = "service.unregistered"
+	ServiceRenamed      = "service.renamed"
+	MDNSPaused          = "mdns.paused"
+	MDNSResumed         = "mdns.resumed"
+	LogLine             = "log.line"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it
+const subscriberBuffer = 64
+
+// Event is a single notification on the bus
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data,omitempty"`
+}
+
+// Bus fans published events out to every matching subscriber
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*subscription]struct{}
+	now  func() time.Time
+}
+
+type subscription struct {
+	ch    chan Event
+	types []string
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[*subscription]struct{}),
+		now:  time.Now,
+	}
+}
+
+// Publish sends an event to all subscribers without blocking; subscribers
+// whose buffers are full miss the event
+func (b *Bus) Publish(eventType string, data any) {
+	ev := Event{Type: eventType, Time: b.now(), Data: data}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs {
+		if !sub.matches(eventType) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of events and a function that ends the
+// subscription. With no types every event is delivered; otherwise a type
+// matches exactly or as a dotted prefix ("service" matches "service.renamed").
+func (b *Bus) Subscribe(types ...string) (<-chan Event, func()) {
+	sub := &subscription{
+		ch:    make(chan Event, subscriberBuffer),
+		types: types,
+	}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+func (s *subscription) matches(eventType string) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+	for _, t := range s.types {
+		if eventType == t || strings.HasPrefix(eventType, t+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package gateway
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// minCompressSize is the smallest response body worth gzipping
+const minCompressSize = 1024
+
+var gzipPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// compress gzips responses for clients that accept it when the body is
+// compressible and at least minCompressSize bytes. Responses that already
+// carry a Content-Encoding (e.g. from a backend behind the proxy) are
+// passed through untouched.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsGzip(r) || r.Method == http.MethodHead ||
+			r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, status: http.StatusOK}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		enc, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(enc), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// isCompressible reports whether a content type benefits from gzip.
+// Event streams are excluded because buffering would delay events.
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json",
+		mediaType == "application/javascript",
+		mediaType == "application/xml",
+		mediaType == "image/svg+xml",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
+
+// compressWriter holds back the status line and the first bytes of the
+// body until it knows whether the response should be gzipped
+type compressWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         []byte
+	decided     bool
+	gz          *gzip.Writer
+}
+
+func (c *compressWriter) WriteHeader(code int) {
+	if isInformational(code) {
+		// e.g. 103 Early Hints; the final status is still to come
+		c.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+	c.status = code
+
+	// Upgrades and bodiless responses can't be compressed
+	if code < 200 || code == http.StatusNoContent || code == http.StatusNotModified {
+		c.decided = true
+		c.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	c.wroteHeader = true
+	if c.decided {
+		if c.gz != nil {
+			return c.gz.Write(b)
+		}
+		return c.ResponseWriter.Write(b)
+	}
+
+	c.buf = append(c.buf, b...)
+	if len(c.buf) >= minCompressSize {
+		if err := c.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// decide picks gzip or passthrough and flushes the buffered body
+func (c *compressWriter) decide() error {
+	c.decided = true
+	h := c.Header()
+
+	if h.Get("Content-Type") == "" && len(c.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(c.buf))
+	}
+
+	if len(c.buf) >= minCompressSize && h.Get("Content-Encoding") == "" && isCompressible(h.Get("Content-Type")) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		h.Add("Vary", "Accept-Encoding")
+		c.gz = gzipPool.Get().(*gzip.Writer)
+		c.gz.Reset(c.ResponseWriter)
+	}
+
+	c.ResponseWriter.WriteHeader(c.status)
+
+	buf := c.buf
+	c.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if c.gz != nil {
+		_, err := c.gz.Write(buf)
+		return err
+	}
+	_, err := c.ResponseWriter.Write(buf)
+	return err
+}
+
+// Flush sends anything buffered so streaming responses aren't held back
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		c.decide()
+	}
+	if c.gz != nil {
+		c.gz.Flush()
+	}
+	http.NewResponseController(c.ResponseWriter).Flush()
+}
+
+// Close finishes the response once the handler returns
+func (c *compressWriter) Close() error {
+	if !c.decided {
+		if !c.wroteHeader {
+			return nil
+		}
+		if err := c.decide(); err != nil {
+			return err
+		}
+	}
+	if c.gz == nil {
+		return nil
+	}
+	err := c.gz.Close()
+	gzipPool.Put(c.gz)
+	c.gz = nil
+	return err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
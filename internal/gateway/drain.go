@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+)
+
+// trackProxy counts in-flight proxy requests so Drain can wait for them,
+// and turns new requests away once draining has begun
+func (g *Gateway) trackProxy(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.drainMu.Lock()
+		if g.draining {
+			g.drainMu.Unlock()
+			w.Header().Set("Connection", "close")
+			http.Error(w, "Gateway is draining for shutdown, try again shortly", http.StatusServiceUnavailable)
+			return
+		}
+		g.inflight.Add(1)
+		g.drainMu.Unlock()
+		defer g.inflight.Done()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Drain stops the proxy from accepting new requests and waits for
+// in-flight ones to finish or for ctx to expire. Call it before Stop so
+// long-lived proxied streams aren't cut off mid-response.
+func (g *Gateway) Drain(ctx context.Context) error {
+	g.drainMu.Lock()
+	g.draining = true
+	g.drainMu.Unlock()
+
+	g.logger.Info("draining proxy requests")
+
+	finished := make(chan struct{})
+	go func() {
+		g.inflight.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -4,6 +4,7 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -11,9 +12,18 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/FABLOUSFALCON/localmesh/internal/events"
+)
+
+// Errors returned by service registry operations
+var (
+	ErrServiceNotFound = errors.New("service not found")
+	ErrServiceExists   = errors.New("service already registered")
 )
 
 // MDNSService represents a service advertised via mDNS
@@ -27,6 +37,8 @@ type MDNSService struct {
 	Tags         []string          `json:"tags"`
 	Metadata     map[string]string `json:"metadata"`
 	Healthy      bool              `json:"healthy"`
+	Streaming    bool              `json:"streaming"`          // Proxy flushes every write instead of buffering
+	Upstream     string            `json:"upstream,omitempty"` // Proxy target URL (default http://IP:Port)
 	RegisteredAt time.Time         `json:"registered_at"`
 }
 
@@ -40,28 +52,57 @@ type Gateway struct {
 	services   map[string]*MDNSService
 	processes  map[string]*exec.Cmd
 	serverMDNS *exec.Cmd // mDNS advertisement for the server itself
+	paused     bool      // advertisements withheld during quiet hours
 	mu         sync.RWMutex
 
 	// Configuration
-	host         string
-	port         int
-	proxyPort    int // Port for reverse proxy (default 80)
-	domain       string
-	readTimeout  time.Duration
-	writeTimeout time.Duration
-
-	logger *slog.Logger
+	host           string
+	port           int
+	proxyPort      int // Port for reverse proxy (default 80)
+	domain         string
+	readTimeout    time.Duration
+	writeTimeout   time.Duration
+	quietHours     *QuietWindow
+	now            func() time.Time
+	detectIP       func() (string, error)
+	startedAt      time.Time
+	requireNetwork bool
+	transport      http.RoundTripper // shared by all .local proxy requests
+	compression    bool
+
+	conns   *connLimiter
+	limiter *rateLimiter // nil unless RateLimit is set
+
+	drainMu  sync.Mutex
+	draining bool           // proxy refuses new requests
+	inflight sync.WaitGroup // proxy requests being served
+	events   *events.Bus
+	metrics  *requestMetrics
+	prom     *promMetrics // nil unless MetricsEnabled
+	done     chan struct{}
+	logger   *slog.Logger
 }
 
 // GatewayConfig configures the gateway
 type GatewayConfig struct {
-	Host         string
-	Port         int
-	ProxyPort    int // Port for reverse proxy (default 80)
-	Domain       string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	Logger       *slog.Logger
+	Host              string
+	Port              int
+	ProxyPort         int    // Port for reverse proxy (default 80)
+	Domain            string // mDNS domain services are published under (default "local")
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	QuietHours        *QuietWindow           // Pause mDNS advertisement inside this window (nil = never)
+	Clock             func() time.Time       // Time source for quiet hours (default time.Now)
+	DetectIP          func() (string, error) // Finds this node's LAN address (default: first non-loopback IPv4)
+	RequireNetwork    bool                   // Fail Start instead of serving locally when mDNS can't be advertised
+	MetricsEnabled    bool                   // Serve Prometheus metrics on GET /metrics
+	MaxConnections    int                    // Cap on concurrent connections across API and proxy (0 = unlimited)
+	ProxyRetries      int                    // Retries for failed GET/HEAD proxy requests (0 = none)
+	ProxyBackoff      time.Duration          // Initial delay between proxy retries, doubled each attempt
+	EnableCompression bool                   // Gzip compressible responses for clients that accept it
+	RateLimit         *RateLimitConfig       // Per-client request limits (nil = unlimited)
+	Events            *events.Bus            // Bus for service and mDNS events (default: private bus)
+	Logger            *slog.Logger
 }
 
 // DefaultGatewayConfig returns sensible defaults
@@ -70,7 +111,7 @@ func DefaultGatewayConfig() GatewayConfig {
 		Host:         "0.0.0.0",
 		Port:         8080,
 		ProxyPort:    8081, // Higher port so no sudo needed
-		Domain:       "campus",
+		Domain:       "local",
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
@@ -83,22 +124,65 @@ func NewGateway(cfg GatewayConfig) *Gateway {
 		logger = slog.Default()
 	}
 
+	now := cfg.Clock
+	if now == nil {
+		now = time.Now
+	}
+
+	detect := cfg.DetectIP
+	if detect == nil {
+		detect = detectIP
+	}
+
 	proxyPort := cfg.ProxyPort
 	if proxyPort == 0 {
 		proxyPort = 80
 	}
 
+	domain := strings.TrimSuffix(cfg.Domain, ".")
+	if domain == "" {
+		domain = "local"
+	}
+
 	g := &Gateway{
-		mux:          http.NewServeMux(),
-		services:     make(map[string]*MDNSService),
-		processes:    make(map[string]*exec.Cmd),
-		host:         cfg.Host,
-		port:         cfg.Port,
-		proxyPort:    proxyPort,
-		domain:       cfg.Domain,
-		readTimeout:  cfg.ReadTimeout,
-		writeTimeout: cfg.WriteTimeout,
-		logger:       logger,
+		mux:            http.NewServeMux(),
+		services:       make(map[string]*MDNSService),
+		processes:      make(map[string]*exec.Cmd),
+		host:           cfg.Host,
+		port:           cfg.Port,
+		proxyPort:      proxyPort,
+		domain:         domain,
+		readTimeout:    cfg.ReadTimeout,
+		writeTimeout:   cfg.WriteTimeout,
+		quietHours:     cfg.QuietHours,
+		now:            now,
+		detectIP:       detect,
+		requireNetwork: cfg.RequireNetwork,
+		compression:    cfg.EnableCompression,
+		conns:          newConnLimiter(cfg.MaxConnections),
+		metrics:        newRequestMetrics(),
+		done:           make(chan struct{}),
+		logger:         logger,
+	}
+
+	g.events = cfg.Events
+	if g.events == nil {
+		g.events = events.NewBus()
+	}
+
+	if cfg.RateLimit != nil {
+		g.limiter = newRateLimiter(*cfg.RateLimit, now)
+	}
+
+	if cfg.MetricsEnabled {
+		g.prom = newPromMetrics(g)
+	}
+
+	g.transport = &retryTransport{
+		base:    http.DefaultTransport,
+		retries: cfg.ProxyRetries,
+		backoff: cfg.ProxyBackoff,
+		onRetry: g.recordRetry,
 	}
 
 	g.setupRoutes()
@@ -108,10 +192,18 @@ func NewGateway(cfg GatewayConfig) *Gateway {
 func (g *Gateway) setupRoutes() {
 	// Health check
 	g.mux.HandleFunc("GET /health", g.handleHealth)
+	g.mux.HandleFunc("GET /api/v1/health/summary", g.handleHealthSummary)
+	g.mux.HandleFunc("GET /api/v1/status", g.handleStatus)
+	g.mux.HandleFunc("GET /api/v1/stats", g.handleStats)
+	g.mux.HandleFunc("GET /api/v1/events", g.handleEvents)
+	if g.prom != nil {
+		g.mux.Handle("GET /metrics", g.prom.handler())
+	}
 
 	// Service registration API
 	g.mux.HandleFunc("POST /api/v1/services/register", g.handleRegister)
 	g.mux.HandleFunc("POST /api/v1/services/unregister", g.handleUnregister)
+	g.mux.HandleFunc("POST /api/v1/services/rename", g.handleRename)
 	g.mux.HandleFunc("GET /api/v1/services", g.handleListServices)
 	g.mux.HandleFunc("GET /api/v1/services/{name}", g.handleGetService)
 
@@ -125,7 +217,7 @@ func (g *Gateway) Start() error {
 
 	g.server = &http.Server{
 		Addr:         addr,
-		Handler:      g.mux,
+		Handler:      g.instrument(g.rateLimit(g.withCompression(g.mux)), nil),
 		ReadTimeout:  g.readTimeout,
 		WriteTimeout: g.writeTimeout,
 	}
@@ -134,12 +226,29 @@ func (g *Gateway) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
+	listener = g.conns.wrap(listener)
 
+	// Advertise LocalMesh server via mDNS so agents can discover it.
+	// Without a network this is best-effort unless the network is required.
+	g.mu.Lock()
+	g.paused = g.inQuietHours()
+	if g.paused {
+		g.logger.Info("mDNS advertisement paused for quiet hours")
+	} else if err := g.advertiseServer(); err != nil {
+		if g.requireNetwork {
+			g.mu.Unlock()
+			listener.Close()
+			return fmt.Errorf("network required: %w", err)
+		}
+		g.logger.Warn("failed to advertise server via mDNS, serving locally only", "error", err)
+	}
+	g.mu.Unlock()
+
+	g.startedAt = time.Now()
 	g.logger.Info("gateway started", "addr", addr)
 
-	// Advertise LocalMesh server via mDNS so agents can discover it
-	if err := g.advertiseServer(); err != nil {
-		g.logger.Warn("failed to advertise server via mDNS", "error", err)
+	if g.quietHours != nil {
+		go g.runQuietHours()
 	}
 
 	go func() {
@@ -156,45 +265,65 @@ func (g *Gateway) Start() error {
 	return nil
 }
 
-// startReverseProxy starts an HTTP reverse proxy on port 80
-// This allows users to access services like http://myapp.local without specifying a port
-func (g *Gateway) startReverseProxy() error {
-	proxyAddr := fmt.Sprintf("%s:%d", g.host, g.proxyPort)
+// ProxyHandler returns the handler the reverse proxy listener serves:
+// service lookup by Host plus rate limiting, compression and metrics
+func (g *Gateway) ProxyHandler() http.Handler {
+	return g.instrument(g.rateLimit(g.withCompression(g.trackProxy(http.HandlerFunc(g.serveProxy)))), g.registeredService)
+}
 
-	proxyHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extract service name from Host header (e.g., "myapp.local" -> "myapp")
-		host := r.Host
-		if colonIdx := strings.Index(host, ":"); colonIdx != -1 {
-			host = host[:colonIdx]
-		}
+// serveProxy forwards a request to the service named by its Host header
+func (g *Gateway) serveProxy(w http.ResponseWriter, r *http.Request) {
+	serviceName := g.serviceFromHost(r)
+	if !validServiceName(serviceName) {
+		http.Error(w, "Invalid service name", http.StatusBadRequest)
+		return
+	}
 
-		// Remove .local suffix to get service name
-		serviceName := strings.TrimSuffix(host, ".local")
+	// Look up the service
+	g.mu.RLock()
+	svc, exists := g.services[serviceName]
+	g.mu.RUnlock()
 
-		// Look up the service
-		g.mu.RLock()
-		svc, exists := g.services[serviceName]
-		g.mu.RUnlock()
+	if !exists {
+		http.Error(w, fmt.Sprintf("Service %q not found", serviceName), http.StatusNotFound)
+		return
+	}
 
-		if !exists {
-			http.Error(w, fmt.Sprintf("Service %q not found", serviceName), http.StatusNotFound)
-			return
-		}
+	// Create reverse proxy to the actual service
+	upstream := svc.Upstream
+	if upstream == "" {
+		upstream = fmt.Sprintf("http://%s:%d", svc.IP, svc.Port)
+	}
+	target, err := url.Parse(upstream)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
 
-		// Create reverse proxy to the actual service
-		target, err := url.Parse(fmt.Sprintf("http://%s:%d", svc.IP, svc.Port))
-		if err != nil {
-			http.Error(w, "Internal error", http.StatusInternalServerError)
-			return
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = g.transport
+	if svc.Streaming {
+		proxy.FlushInterval = -1
+	}
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		stripInternalHeaders(req.Header)
+		if svc.Upstream != "" {
+			req.Host = target.Host // Configured URLs may be name-based virtual hosts
 		}
+	}
+	proxy.ServeHTTP(w, r)
+}
 
-		proxy := httputil.NewSingleHostReverseProxy(target)
-		proxy.ServeHTTP(w, r)
-	})
+// startReverseProxy starts an HTTP reverse proxy on port 80
+// This allows users to access services like http://myapp.local without specifying a port
+func (g *Gateway) startReverseProxy() error {
+	proxyAddr := fmt.Sprintf("%s:%d", g.host, g.proxyPort)
 
 	g.proxyServer = &http.Server{
 		Addr:         proxyAddr,
-		Handler:      proxyHandler,
+		Handler:      g.ProxyHandler(),
 		ReadTimeout:  g.readTimeout,
 		WriteTimeout: g.writeTimeout,
 	}
@@ -203,6 +332,7 @@ func (g *Gateway) startReverseProxy() error {
 	if err != nil {
 		return fmt.Errorf("failed to listen on proxy port %d: %w (try running with sudo or use a port > 1024)", g.proxyPort, err)
 	}
+	listener = g.conns.wrap(listener)
 
 	g.logger.Info("reverse proxy started", "addr", proxyAddr)
 
@@ -215,9 +345,78 @@ func (g *Gateway) startReverseProxy() error {
 	return nil
 }
 
-// advertiseServer advertises the LocalMesh server via mDNS using avahi-publish-service
+// withCompression wraps h in the gzip middleware when compression is enabled
+func (g *Gateway) withCompression(h http.Handler) http.Handler {
+	if !g.compression {
+		return h
+	}
+	return compress(h)
+}
+
+// serviceFromHost extracts the service name from the Host header
+// (e.g., "myapp.local:8081" -> "myapp")
+func (g *Gateway) serviceFromHost(r *http.Request) string {
+	host := r.Host
+	if colonIdx := strings.Index(host, ":"); colonIdx != -1 {
+		host = host[:colonIdx]
+	}
+	return strings.TrimSuffix(strings.ToLower(host), "."+g.domain)
+}
+
+// hostname returns the name a service is published under, e.g. myapp.local
+func (g *Gateway) hostname(name string) string {
+	return name + "." + g.domain
+}
+
+// serviceURL returns the proxy URL for a registered service
+func (g *Gateway) serviceURL(name string) string {
+	return ServiceURL(g.domain, g.proxyPort, name)
+}
+
+// ServiceURL returns the URL a service is reachable at through the reverse
+// proxy, e.g. http://attendance.local:8081, leaving out port 80
+func ServiceURL(domain string, proxyPort int, name string) string {
+	return buildURL("http", name+"."+strings.TrimSuffix(domain, "."), proxyPort)
+}
+
+// buildURL joins scheme, host and port, leaving out ports 80/443 where
+// they are the scheme default
+func buildURL(scheme, host string, port int) string {
+	if (scheme == "http" && port == 80) || (scheme == "https" && port == 443) {
+		return scheme + "://" + host
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, host, port)
+}
+
+// registeredService returns the service a proxy request targets, or "" if
+// no such service is registered, so arbitrary Host headers can't grow metrics
+func (g *Gateway) registeredService(r *http.Request) string {
+	name := g.serviceFromHost(r)
+
+	g.mu.RLock()
+	_, exists := g.services[name]
+	g.mu.RUnlock()
+
+	if !exists {
+		return ""
+	}
+	return name
+}
+
+// stripInternalHeaders removes client-supplied X-LocalMesh-* headers so
+// backends can trust that any they receive were set by the gateway
+func stripInternalHeaders(h http.Header) {
+	for key := range h {
+		if strings.HasPrefix(strings.ToLower(key), "x-localmesh-") {
+			delete(h, key) // Not h.Del, which misses non-canonical keys
+		}
+	}
+}
+
+// advertiseServer advertises the LocalMesh server via mDNS using avahi-publish-service.
+// Callers must hold g.mu.
 func (g *Gateway) advertiseServer() error {
-	ip, err := detectIP()
+	ip, err := g.detectIP()
 	if err != nil {
 		return fmt.Errorf("failed to detect IP: %w", err)
 	}
@@ -240,20 +439,15 @@ func (g *Gateway) advertiseServer() error {
 
 // Stop gracefully shuts down the gateway
 func (g *Gateway) Stop(ctx context.Context) error {
-	// Stop server mDNS advertisement
-	if g.serverMDNS != nil && g.serverMDNS.Process != nil {
-		g.serverMDNS.Process.Kill()
-		g.serverMDNS = nil
+	select {
+	case <-g.done:
+	default:
+		close(g.done)
 	}
 
-	// Stop all service mDNS advertisements
+	// Stop server and service mDNS advertisements
 	g.mu.Lock()
-	for name, cmd := range g.processes {
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		delete(g.processes, name)
-	}
+	g.stopAdvertising()
 	g.mu.Unlock()
 
 	// Stop reverse proxy
@@ -293,56 +487,91 @@ func (g *Gateway) StartHealthChecks(interval time.Duration) {
 	// No-op
 }
 
-// AdvertiseExternalService advertises a service via mDNS using avahi-publish-address
-func (g *Gateway) AdvertiseExternalService(name, serviceType string, port int, hostIP string, txtRecords map[string]string) error {
+// AdvertiseExternalService advertises a service via mDNS using avahi-publish-address.
+// Streaming services are proxied with immediate flushing (SSE, long-poll).
+func (g *Gateway) AdvertiseExternalService(name, serviceType string, port int, hostIP string, txtRecords map[string]string, streaming bool) error {
+	svc := &MDNSService{
+		Name:      name,
+		Port:      port,
+		IP:        hostIP,
+		Metadata:  txtRecords,
+		Streaming: streaming,
+	}
+	if desc, ok := txtRecords["description"]; ok {
+		svc.Description = desc
+	}
+	return g.advertise(svc)
+}
+
+// AdvertiseUpstream advertises name at this node's address and proxies it
+// to upstream, an absolute http or https URL such as a service configured
+// in localmesh.yaml
+func (g *Gateway) AdvertiseUpstream(name, upstream, description string, tags []string) error {
+	u, err := url.Parse(upstream)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("service %q: invalid upstream %q", name, upstream)
+	}
+
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if p := u.Port(); p != "" {
+		if port, err = strconv.Atoi(p); err != nil {
+			return fmt.Errorf("service %q: invalid upstream port %q", name, p)
+		}
+	}
+
+	return g.advertise(&MDNSService{
+		Name:        name,
+		Port:        port,
+		Upstream:    upstream,
+		Description: description,
+		Tags:        tags,
+	})
+}
+
+// advertise publishes svc's hostname and adds it to the registry. An empty
+// svc.IP is filled with this node's address.
+func (g *Gateway) advertise(svc *MDNSService) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	// Check if already registered
-	if _, exists := g.services[name]; exists {
-		return fmt.Errorf("service %q already registered", name)
+	if _, exists := g.services[svc.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrServiceExists, svc.Name)
 	}
 
 	// Get IP if not provided
-	ip := hostIP
-	if ip == "" {
-		var err error
-		ip, err = detectIP()
+	if svc.IP == "" {
+		ip, err := g.detectIP()
 		if err != nil {
 			return fmt.Errorf("failed to detect IP: %w", err)
 		}
+		svc.IP = ip
 	}
 
-	// Build hostname
-	hostname := fmt.Sprintf("%s.local", name)
-	url := fmt.Sprintf("http://%s", hostname) // No port needed - reverse proxy handles it
+	svc.Hostname = g.hostname(svc.Name)
+	svc.URL = g.serviceURL(svc.Name)
+	svc.Healthy = true
+	svc.RegisteredAt = time.Now()
 
-	// Start avahi-publish-address
-	cmd := exec.Command("avahi-publish-address", "-R", hostname, ip)
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start avahi-publish-address: %w", err)
+	// Start avahi-publish-address, unless quiet hours are in effect
+	var cmd *exec.Cmd
+	if !g.paused {
+		var err error
+		if cmd, err = publishAddress(svc.Hostname, svc.IP); err != nil {
+			return err
+		}
 	}
 
-	// Track service
-	svc := &MDNSService{
-		Name:         name,
-		Port:         port,
-		IP:           ip,
-		Hostname:     hostname,
-		URL:          url,
-		Metadata:     txtRecords,
-		Healthy:      true,
-		RegisteredAt: time.Now(),
+	g.services[svc.Name] = svc
+	if cmd != nil {
+		g.processes[svc.Name] = cmd
 	}
 
-	if desc, ok := txtRecords["description"]; ok {
-		svc.Description = desc
-	}
-
-	g.services[name] = svc
-	g.processes[name] = cmd
-
-	g.logger.Info("mDNS advertised", "name", name, "hostname", hostname, "ip", ip, "port", port)
+	g.logger.Info("mDNS advertised", "name", svc.Name, "hostname", svc.Hostname, "ip", svc.IP, "port", svc.Port)
+	g.events.Publish(events.ServiceRegistered, *svc)
 	return nil
 }
 
@@ -351,12 +580,11 @@ func (g *Gateway) StopAdvertisingService(name string) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	cmd, exists := g.processes[name]
-	if !exists {
+	if _, exists := g.services[name]; !exists {
 		return fmt.Errorf("service %q not found", name)
 	}
 
-	if cmd.Process != nil {
+	if cmd, ok := g.processes[name]; ok && cmd.Process != nil {
 		cmd.Process.Kill()
 	}
 
@@ -364,6 +592,53 @@ func (g *Gateway) StopAdvertisingService(name string) error {
 	delete(g.services, name)
 
 	g.logger.Info("mDNS stopped", "name", name)
+	g.events.Publish(events.ServiceUnregistered, map[string]string{"name": name})
+	return nil
+}
+
+// RenameService moves a service to a new name and re-advertises it under the new .local hostname
+func (g *Gateway) RenameService(oldName, newName string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	svc, exists := g.services[oldName]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, oldName)
+	}
+	if _, exists := g.services[newName]; exists {
+		return fmt.Errorf("%w: %s", ErrServiceExists, newName)
+	}
+
+	// Publish the new hostname before dropping the old one so the service stays reachable
+	hostname := g.hostname(newName)
+	var cmd *exec.Cmd
+	if !g.paused {
+		var err error
+		if cmd, err = publishAddress(hostname, svc.IP); err != nil {
+			return err
+		}
+	}
+
+	if old, ok := g.processes[oldName]; ok && old.Process != nil {
+		old.Process.Kill()
+	}
+	delete(g.processes, oldName)
+	delete(g.services, oldName)
+
+	// Copy so readers holding the old entry never see a half-renamed service
+	renamed := *svc
+	renamed.Name = newName
+	renamed.Hostname = hostname
+	renamed.URL = g.serviceURL(newName)
+
+	g.services[newName] = &renamed
+	if cmd != nil {
+		g.processes[newName] = cmd
+	}
+	g.metrics.rename(oldName, newName)
+
+	g.logger.Info("mDNS renamed", "old", oldName, "new", newName, "hostname", hostname)
+	g.events.Publish(events.ServiceRenamed, map[string]string{"name": oldName, "new_name": newName})
 	return nil
 }
 
@@ -376,6 +651,104 @@ func (g *Gateway) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (g *Gateway) handleHealthSummary(w http.ResponseWriter, r *http.Request) {
+	c := g.countHealth()
+
+	g.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"status": rollupStatus(c),
+		"services": map[string]int{
+			"total":    c.total,
+			"healthy":  c.healthy,
+			"degraded": c.degraded,
+			"offline":  c.offline,
+		},
+		"time": time.Now().Format(time.RFC3339),
+	})
+}
+
+// A healthy service counts as degraded once it has served at least
+// degradedMinRequests proxied requests and degradedErrorRate of them were 5xx
+const (
+	degradedMinRequests = 20
+	degradedErrorRate   = 0.1
+)
+
+// healthCounts buckets the registered services by health
+type healthCounts struct {
+	total, healthy, degraded, offline int
+}
+
+// countHealth classifies every registered service using its health flag
+// and proxied error rate
+func (g *Gateway) countHealth() healthCounts {
+	_, byService := g.metrics.snapshot()
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	c := healthCounts{total: len(g.services)}
+	for name, svc := range g.services {
+		m := byService[name]
+		switch {
+		case !svc.Healthy:
+			c.offline++
+		case m.Requests >= degradedMinRequests && float64(m.Errors) >= degradedErrorRate*float64(m.Requests):
+			c.degraded++
+		default:
+			c.healthy++
+		}
+	}
+	return c
+}
+
+// rollupStatus derives an overall status: green when every service is
+// healthy, red when half or more are offline, yellow in between
+func rollupStatus(c healthCounts) string {
+	switch {
+	case c.healthy == c.total:
+		return "green"
+	case c.offline*2 >= c.total:
+		return "red"
+	default:
+		return "yellow"
+	}
+}
+
+func (g *Gateway) handleStatus(w http.ResponseWriter, r *http.Request) {
+	g.mu.RLock()
+	services := len(g.services)
+	paused := g.paused
+	g.mu.RUnlock()
+
+	uptime := time.Since(g.startedAt)
+
+	g.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"status":         "running",
+		"started_at":     g.startedAt.Format(time.RFC3339),
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"services":       services,
+		"mdns_paused":    paused,
+	})
+}
+
+func (g *Gateway) handleStats(w http.ResponseWriter, r *http.Request) {
+	c := g.countHealth()
+	byStatus, byService := g.metrics.snapshot()
+
+	g.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"services_total":       c.total,
+		"services_healthy":     c.total - c.offline, // Degraded services are still up
+		"requests_total":       g.metrics.total.Load(),
+		"requests_active":      g.metrics.active.Load(),
+		"requests_by_status":   byStatus,
+		"requests_by_service":  byService,
+		"connections_active":   g.conns.active.Load(),
+		"connections_rejected": g.conns.rejected.Load(),
+		"proxy_retries":        g.metrics.retries.Load(),
+	})
+}
+
 func (g *Gateway) handleRegister(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name        string            `json:"name"`
@@ -384,6 +757,7 @@ func (g *Gateway) handleRegister(w http.ResponseWriter, r *http.Request) {
 		Description string            `json:"description"`
 		Tags        []string          `json:"tags"`
 		Metadata    map[string]string `json:"metadata"`
+		Streaming   bool              `json:"streaming"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -391,12 +765,11 @@ func (g *Gateway) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		g.jsonError(w, http.StatusBadRequest, "name is required")
-		return
-	}
-	if req.Port <= 0 || req.Port > 65535 {
-		g.jsonError(w, http.StatusBadRequest, "port must be between 1 and 65535")
+	if err := validateRegistration(req.Name, req.Port, req.IP); err != nil {
+		g.jsonResponse(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  err.Error(),
+			"fields": err,
+		})
 		return
 	}
 
@@ -409,8 +782,12 @@ func (g *Gateway) handleRegister(w http.ResponseWriter, r *http.Request) {
 		txtRecords["description"] = req.Description
 	}
 
-	if err := g.AdvertiseExternalService(req.Name, "_http._tcp", req.Port, req.IP, txtRecords); err != nil {
-		g.jsonError(w, http.StatusInternalServerError, err.Error())
+	if err := g.AdvertiseExternalService(req.Name, "_http._tcp", req.Port, req.IP, txtRecords, req.Streaming); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrServiceExists) {
+			status = http.StatusConflict
+		}
+		g.jsonError(w, status, err.Error())
 		return
 	}
 
@@ -452,6 +829,53 @@ func (g *Gateway) handleUnregister(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (g *Gateway) handleRename(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name    string `json:"name"`
+		NewName string `json:"new_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		g.jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Name == "" || req.NewName == "" {
+		g.jsonError(w, http.StatusBadRequest, "name and new_name are required")
+		return
+	}
+	if !validServiceName(req.NewName) {
+		g.jsonResponse(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  "invalid new_name",
+			"fields": ValidationError{{Field: "new_name", Message: serviceNameRule}},
+		})
+		return
+	}
+
+	if err := g.RenameService(req.Name, req.NewName); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrServiceNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrServiceExists):
+			status = http.StatusConflict
+		}
+		g.jsonError(w, status, err.Error())
+		return
+	}
+
+	g.mu.RLock()
+	svc := g.services[req.NewName]
+	g.mu.RUnlock()
+
+	g.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"hostname": svc.Hostname,
+		"url":      svc.URL,
+		"message":  fmt.Sprintf("Service %s renamed to %s", req.Name, req.NewName),
+	})
+}
+
 func (g *Gateway) handleListServices(w http.ResponseWriter, r *http.Request) {
 	g.mu.RLock()
 	services := make([]*MDNSService, 0, len(g.services))
@@ -499,6 +923,15 @@ func (g *Gateway) jsonError(w http.ResponseWriter, status int, message string) {
 	g.jsonResponse(w, status, map[string]string{"error": message})
 }
 
+// publishAddress starts avahi-publish-address for hostname → ip
+func publishAddress(hostname, ip string) (*exec.Cmd, error) {
+	cmd := exec.Command("avahi-publish-address", "-R", hostname, ip)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start avahi-publish-address: %w", err)
+	}
+	return cmd, nil
+}
+
 // detectIP returns the local IP address
 func detectIP() (string, error) {
 	addrs, err := net.InterfaceAddrs()
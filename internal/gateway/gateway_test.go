@@ -0,0 +1,240 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		scheme string
+		port   int
+		want   string
+	}{
+		{"http", 80, "http://campus.local"},
+		{"https", 443, "https://campus.local"},
+		{"http", 8081, "http://campus.local:8081"},
+		{"https", 8443, "https://campus.local:8443"},
+		{"http", 443, "http://campus.local:443"},
+	}
+
+	for _, tt := range tests {
+		if got := buildURL(tt.scheme, "campus.local", tt.port); got != tt.want {
+			t.Errorf("buildURL(%q, %d) = %q, want %q", tt.scheme, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestServiceURLUsesDomain(t *testing.T) {
+	g := NewGateway(GatewayConfig{Domain: "lan.", ProxyPort: 8081})
+
+	if got, want := g.serviceURL("attendance"), "http://attendance.lan:8081"; got != want {
+		t.Errorf("serviceURL = %q, want %q", got, want)
+	}
+	if got, want := g.hostname("attendance"), "attendance.lan"; got != want {
+		t.Errorf("hostname = %q, want %q", got, want)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Host = "Attendance.LAN:8081"
+	if got := g.serviceFromHost(r); got != "attendance" {
+		t.Errorf("serviceFromHost(%q) = %q, want %q", r.Host, got, "attendance")
+	}
+}
+
+// fakeAvahi puts stand-in avahi publishers on PATH that run until killed
+func fakeAvahi(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"avahi-publish-address", "avahi-publish-service"} {
+		script := "#!/bin/sh\nexec sleep 60\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func newTestGateway(t *testing.T, cfg GatewayConfig) *Gateway {
+	t.Helper()
+	if cfg.Logger == nil {
+		cfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	g := NewGateway(cfg)
+	t.Cleanup(func() {
+		g.mu.Lock()
+		g.stopAdvertising()
+		g.mu.Unlock()
+	})
+	return g
+}
+
+func postJSON(g *Gateway, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	g.mux.ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+	return rec
+}
+
+func TestRenameMovesRegistryKey(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081})
+
+	if err := g.AdvertiseExternalService("wiki", "_http._tcp", 3000, "10.0.0.4", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AdvertiseExternalService("docs", "_http._tcp", 3001, "10.0.0.4", nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := postJSON(g, "/api/v1/services/rename", `{"name":"wiki","new_name":"library-wiki"}`)
+	if rec.Code != 200 {
+		t.Fatalf("rename: %d %s", rec.Code, rec.Body)
+	}
+
+	if _, ok := g.services["wiki"]; ok {
+		t.Error("old name still registered")
+	}
+	svc, ok := g.services["library-wiki"]
+	if !ok {
+		t.Fatal("new name not registered")
+	}
+	if svc.Hostname != "library-wiki.local" || svc.Port != 3000 {
+		t.Errorf("renamed service = %+v", svc)
+	}
+	if _, ok := g.processes["library-wiki"]; !ok {
+		t.Error("new hostname not advertised")
+	}
+
+	if rec := postJSON(g, "/api/v1/services/rename", `{"name":"library-wiki","new_name":"docs"}`); rec.Code != 409 {
+		t.Errorf("rename onto existing name: %d, want 409", rec.Code)
+	}
+	if rec := postJSON(g, "/api/v1/services/rename", `{"name":"missing","new_name":"other"}`); rec.Code != 404 {
+		t.Errorf("rename of unknown service: %d, want 404", rec.Code)
+	}
+}
+
+func TestRenamePublishFailureIsServerError(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{})
+	if err := g.AdvertiseExternalService("wiki", "_http._tcp", 3000, "10.0.0.4", nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", t.TempDir()) // avahi-publish-address no longer found
+	if rec := postJSON(g, "/api/v1/services/rename", `{"name":"wiki","new_name":"docs"}`); rec.Code != 500 {
+		t.Errorf("rename with avahi failing: %d, want 500", rec.Code)
+	}
+	if _, ok := g.services["wiki"]; !ok {
+		t.Error("failed rename dropped the service")
+	}
+}
+
+func TestRegisterDuplicateIsConflict(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{})
+
+	body := `{"name":"wiki","port":3000,"ip":"10.0.0.4"}`
+	if rec := postJSON(g, "/api/v1/services/register", body); rec.Code != 200 {
+		t.Fatalf("first register: %d", rec.Code)
+	}
+	if rec := postJSON(g, "/api/v1/services/register", body); rec.Code != 409 {
+		t.Errorf("duplicate register: %d, want 409", rec.Code)
+	}
+
+	t.Setenv("PATH", t.TempDir()) // avahi-publish-address no longer found
+	if rec := postJSON(g, "/api/v1/services/register", `{"name":"docs","port":3001,"ip":"10.0.0.4"}`); rec.Code != 500 {
+		t.Errorf("register with avahi failing: %d, want 500", rec.Code)
+	}
+}
+
+func TestDomainDefaultsToLocal(t *testing.T) {
+	g := NewGateway(GatewayConfig{})
+
+	if got, want := g.serviceURL("wiki"), "http://wiki.local"; got != want {
+		t.Errorf("serviceURL = %q, want %q", got, want)
+	}
+}
+
+func TestRenameKeepsServiceMetrics(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{})
+	if err := g.AdvertiseExternalService("wiki", "_http._tcp", 3000, "10.0.0.4", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	g.metrics.record("wiki", 200)
+	g.metrics.record("wiki", 502)
+
+	if err := g.RenameService("wiki", "library-wiki"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, byService := g.metrics.snapshot()
+	if _, ok := byService["wiki"]; ok {
+		t.Error("metrics still recorded under the old name")
+	}
+	if got, want := byService["library-wiki"], (ServiceMetrics{Requests: 2, Errors: 1}); got != want {
+		t.Errorf("metrics after rename = %+v, want %+v", got, want)
+	}
+}
+
+// freePort returns a loopback port nothing is listening on
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestStartWithoutNetwork(t *testing.T) {
+	fakeAvahi(t)
+	noNetwork := func() (string, error) { return "", errors.New("no suitable IP address found") }
+
+	for _, required := range []bool{false, true} {
+		var logs bytes.Buffer
+		port := freePort(t)
+		g := NewGateway(GatewayConfig{
+			Host:           "127.0.0.1",
+			Port:           port,
+			ProxyPort:      freePort(t),
+			DetectIP:       noNetwork,
+			RequireNetwork: required,
+			Logger:         slog.New(slog.NewTextHandler(&logs, nil)),
+		})
+
+		err := g.Start()
+		if required {
+			if err == nil {
+				t.Error("Start succeeded without a network when one is required")
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("best-effort Start: %v", err)
+		}
+		resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/health", port))
+		if err != nil {
+			t.Errorf("gateway not serving locally: %v", err)
+		} else if resp.Body.Close(); resp.StatusCode != http.StatusOK {
+			t.Errorf("health after best-effort Start = %d", resp.StatusCode)
+		}
+		if !strings.Contains(logs.String(), "level=WARN msg=\"failed to advertise server via mDNS") {
+			t.Errorf("no warning logged:\n%s", logs.String())
+		}
+		g.Stop(context.Background())
+	}
+}
@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthSummaryRollup(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081})
+
+	for i, name := range []string{"wiki", "docs", "grades", "library"} {
+		if err := g.AdvertiseExternalService(name, "_http._tcp", 3000+i, "10.0.0.4", nil, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary := func() (string, map[string]int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		g.mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/health/summary", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("summary = %d: %s", rec.Code, rec.Body)
+		}
+		var body struct {
+			Status   string         `json:"status"`
+			Services map[string]int `json:"services"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Status, body.Services
+	}
+	expect := func(status string, healthy, degraded, offline int) {
+		t.Helper()
+		gotStatus, got := summary()
+		want := map[string]int{"total": 4, "healthy": healthy, "degraded": degraded, "offline": offline}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("services.%s = %d, want %d", k, got[k], v)
+			}
+		}
+		if gotStatus != status {
+			t.Errorf("status = %q, want %q", gotStatus, status)
+		}
+	}
+
+	expect("green", 4, 0, 0)
+
+	// 3 of 20 requests failing crosses the degraded threshold
+	for i := 0; i < 20; i++ {
+		status := http.StatusOK
+		if i < 3 {
+			status = http.StatusBadGateway
+		}
+		g.metrics.record("docs", status)
+	}
+	expect("yellow", 3, 1, 0)
+
+	setHealthy := func(name string, healthy bool) {
+		g.mu.Lock()
+		g.services[name].Healthy = healthy
+		g.mu.Unlock()
+	}
+	setHealthy("grades", false)
+	expect("yellow", 2, 1, 1)
+
+	setHealthy("library", false)
+	expect("red", 1, 1, 2)
+}
+
+func TestRollupStatus(t *testing.T) {
+	tests := []struct {
+		counts healthCounts
+		want   string
+	}{
+		{healthCounts{}, "green"},
+		{healthCounts{total: 3, healthy: 3}, "green"},
+		{healthCounts{total: 3, healthy: 2, degraded: 1}, "yellow"},
+		{healthCounts{total: 3, healthy: 2, offline: 1}, "yellow"},
+		{healthCounts{total: 3, degraded: 3}, "yellow"},
+		{healthCounts{total: 4, healthy: 2, offline: 2}, "red"},
+		{healthCounts{total: 1, offline: 1}, "red"},
+	}
+
+	for _, tt := range tests {
+		if got := rollupStatus(tt.counts); got != tt.want {
+			t.Errorf("rollupStatus(%+v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
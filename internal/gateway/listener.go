@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// overLimitResponse is written to connections rejected by the limiter
+const overLimitResponse = "HTTP/1.1 503 Service Unavailable\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"Connection: close\r\n" +
+	"Content-Length: 28\r\n" +
+	"\r\n" +
+	"too many connections, retry\n"
+
+// connLimiter counts open connections across the gateway's listeners and,
+// when max > 0, caps them. Connections beyond the cap are answered with a
+// 503 and closed straight away so they don't hold file descriptors.
+type connLimiter struct {
+	max      int64
+	active   atomic.Int64
+	rejected atomic.Int64
+}
+
+func newConnLimiter(max int) *connLimiter {
+	return &connLimiter{max: int64(max)}
+}
+
+// wrap returns a listener whose connections are counted against the limit
+func (l *connLimiter) wrap(ln net.Listener) net.Listener {
+	return &limitListener{Listener: ln, limiter: l}
+}
+
+// admit reserves a connection slot, reporting false when the cap is reached
+func (l *connLimiter) admit() bool {
+	n := l.active.Add(1)
+	if l.max > 0 && n > l.max {
+		l.active.Add(-1)
+		return false
+	}
+	return true
+}
+
+type limitListener struct {
+	net.Listener
+	limiter *connLimiter
+}
+
+func (ll *limitListener) Accept() (net.Conn, error) {
+	for {
+		c, err := ll.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if ll.limiter.admit() {
+			return &limitConn{Conn: c, limiter: ll.limiter}, nil
+		}
+
+		ll.limiter.rejected.Add(1)
+		c.SetWriteDeadline(time.Now().Add(time.Second))
+		c.Write([]byte(overLimitResponse))
+		c.Close()
+	}
+}
+
+type limitConn struct {
+	net.Conn
+	limiter   *connLimiter
+	closeOnce sync.Once
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() { c.limiter.active.Add(-1) })
+	return err
+}
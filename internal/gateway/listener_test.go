@@ -0,0 +1,62 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnLimitRejectsOverflow(t *testing.T) {
+	const max = 2
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081, MaxConnections: max})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: g.mux}
+	go srv.Serve(g.conns.wrap(ln))
+	defer srv.Close()
+
+	for i := 0; i < max; i++ {
+		c, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+	}
+
+	// Accept is serial, so once the extra connection is answered the first
+	// max connections have been admitted
+	extra, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer extra.Close()
+	extra.SetReadDeadline(time.Now().Add(2 * time.Second))
+	resp, err := io.ReadAll(extra) // Returns at EOF, once the gateway closes it
+	if err != nil {
+		t.Fatalf("reading rejected connection: %v", err)
+	}
+	if !strings.HasPrefix(string(resp), "HTTP/1.1 503 ") {
+		t.Errorf("rejected connection got %q, want a 503", resp)
+	}
+
+	rec := httptest.NewRecorder()
+	g.mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/stats", nil))
+	var stats struct {
+		Active   int64 `json:"connections_active"`
+		Rejected int64 `json:"connections_rejected"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Active != max || stats.Rejected != 1 {
+		t.Errorf("stats active=%d rejected=%d, want active=%d rejected=1", stats.Active, stats.Rejected, max)
+	}
+}
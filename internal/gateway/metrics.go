@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// requestMetrics tracks request counters across the API server and proxy
+type requestMetrics struct {
+	total   atomic.Int64
+	active  atomic.Int64
+	retries atomic.Int64
+
+	mu        sync.Mutex
+	byStatus  map[int]int64
+	byService map[string]*ServiceMetrics
+}
+
+// ServiceMetrics holds request counters for a single proxied service
+type ServiceMetrics struct {
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"` // 5xx responses
+}
+
+func newRequestMetrics() *requestMetrics {
+	return &requestMetrics{
+		byStatus:  make(map[int]int64),
+		byService: make(map[string]*ServiceMetrics),
+	}
+}
+
+func (m *requestMetrics) record(service string, status int) {
+	m.total.Add(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.byStatus[status]++
+	if service == "" {
+		return
+	}
+	sm, ok := m.byService[service]
+	if !ok {
+		sm = &ServiceMetrics{}
+		m.byService[service] = sm
+	}
+	sm.Requests++
+	if status >= 500 {
+		sm.Errors++
+	}
+}
+
+// rename moves a service's counters to its new name, replacing any left
+// over from an earlier service that used that name
+func (m *requestMetrics) rename(oldName, newName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if sm, ok := m.byService[oldName]; ok {
+		m.byService[newName] = sm
+		delete(m.byService, oldName)
+	}
+}
+
+// snapshot returns copies of the status and per-service counters
+func (m *requestMetrics) snapshot() (map[int]int64, map[string]ServiceMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	byStatus := make(map[int]int64, len(m.byStatus))
+	for code, n := range m.byStatus {
+		byStatus[code] = n
+	}
+	byService := make(map[string]ServiceMetrics, len(m.byService))
+	for name, sm := range m.byService {
+		byService[name] = *sm
+	}
+	return byStatus, byService
+}
+
+// recordRetry counts and logs a proxy retry attempt
+func (g *Gateway) recordRetry(r *http.Request, attempt int, reason string) {
+	g.metrics.retries.Add(1)
+	if g.prom != nil {
+		g.prom.retries.Inc()
+	}
+	g.logger.Warn("retrying proxied request", "host", r.Host, "method", r.Method, "path", r.URL.Path, "attempt", attempt, "reason", reason)
+}
+
+// instrument wraps next to count requests. serviceOf names the service a
+// request belongs to, or is nil for gateway API traffic.
+func (g *Gateway) instrument(next http.Handler, serviceOf func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.metrics.active.Add(1)
+		defer g.metrics.active.Add(-1)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		service := ""
+		if serviceOf != nil {
+			service = serviceOf(r)
+		}
+		g.metrics.record(service, rec.status)
+		if g.prom != nil {
+			g.prom.observe(r.Method, service, rec.status, time.Since(start))
+		}
+	})
+}
+
+// statusRecorder captures the response status code
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if !s.wroteHeader && !isInformational(code) {
+		s.status = code
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// isInformational reports whether code is a 1xx status that precedes the
+// final response. 101 Switching Protocols is final for the connection.
+func isInformational(code int) bool {
+	return code >= 100 && code < 200 && code != http.StatusSwitchingProtocols
+}
+
+// Unwrap lets http.ResponseController reach Flush and Hijack on the
+// underlying writer, which the reverse proxy needs for streaming and upgrades
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// promMetrics holds the Prometheus collectors served on /metrics
+type promMetrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	retries  prometheus.Counter
+}
+
+func newPromMetrics(g *Gateway) *promMetrics {
+	p := &promMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "localmesh_requests_total",
+			Help: "Requests handled by the gateway API and .local proxy.",
+		}, []string{"method", "status", "service"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "localmesh_request_duration_seconds",
+			Help:    "Request latency for the gateway API and .local proxy.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"service"}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "localmesh_proxy_retries_total",
+			Help: "Idempotent proxy requests retried after a transport error or 502.",
+		}),
+	}
+
+	servicesRegistered := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "localmesh_services_registered",
+		Help: "Services currently registered with the gateway.",
+	}, func() float64 {
+		g.mu.RLock()
+		defer g.mu.RUnlock()
+		return float64(len(g.services))
+	})
+
+	p.registry.MustRegister(
+		p.requests,
+		p.duration,
+		p.retries,
+		servicesRegistered,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return p
+}
+
+func (p *promMetrics) observe(method, service string, status int, elapsed time.Duration) {
+	p.requests.WithLabelValues(method, strconv.Itoa(status), service).Inc()
+	p.duration.WithLabelValues(service).Observe(elapsed.Seconds())
+}
+
+func (p *promMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
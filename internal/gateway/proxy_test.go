@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/localmesh/internal/events"
+)
+
+func TestRegisterStreamingEvent(t *testing.T) {
+	fakeAvahi(t)
+	bus := events.NewBus()
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081, Events: bus})
+
+	ch, unsubscribe := bus.Subscribe(events.ServiceRegistered)
+	defer unsubscribe()
+
+	rec := postJSON(g, "/api/v1/services/register", `{"name":"feed","port":3000,"ip":"10.0.0.4","streaming":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("register = %d: %s", rec.Code, rec.Body)
+	}
+
+	select {
+	case ev := <-ch:
+		if svc := ev.Data.(MDNSService); !svc.Streaming {
+			t.Error("service.registered event has Streaming = false")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no service.registered event")
+	}
+}
+
+func TestProxyStreamsFlushImmediately(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081})
+
+	// A plain-text response with a Content-Length is one the reverse proxy
+	// would otherwise buffer. The backend sends the first chunk, then holds
+	// the rest back until the test has seen that chunk through the proxy.
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Length", strconv.Itoa(len("first\nsecond\n")))
+		fmt.Fprint(w, "first\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		fmt.Fprint(w, "second\n")
+	}))
+	defer backend.Close()
+
+	host, port, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	rec := postJSON(g, "/api/v1/services/register",
+		fmt.Sprintf(`{"name":"feed","port":%d,"ip":%q,"streaming":true}`, portNum, host))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("register = %d: %s", rec.Code, rec.Body)
+	}
+
+	proxy := httptest.NewServer(http.HandlerFunc(g.serveProxy))
+	defer proxy.Close()
+
+	// Headers are buffered along with the body, so the request itself
+	// only returns once something is flushed
+	line := make(chan string, 1)
+	go func() {
+		req, _ := http.NewRequest("GET", proxy.URL, nil)
+		req.Host = "feed.local"
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			line <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		s, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		line <- s
+	}()
+	defer close(release) // Let the backend finish before the servers close
+
+	select {
+	case got := <-line:
+		if got != "first\n" {
+			t.Errorf("first chunk = %q, want %q", got, "first\n")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("first chunk was not flushed before the backend finished")
+	}
+}
+
+func TestProxyStripsInternalHeaders(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081})
+
+	var got http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer backend.Close()
+
+	host, port, _ := net.SplitHostPort(backend.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	if err := g.AdvertiseExternalService("grades", "_http._tcp", portNum, host, nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "grades.local"
+	req.Header.Set("X-LocalMesh-Roles", "admin")
+	req.Header["x-localmesh-zone"] = []string{"staff"} // Non-canonical casing
+	req.Header.Set("X-Request-Id", "abc")
+	g.serveProxy(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("proxied request = %d: %s", rec.Code, rec.Body)
+	}
+	for key := range got {
+		if strings.HasPrefix(strings.ToLower(key), "x-localmesh-") {
+			t.Errorf("backend received client header %s: %q", key, got[key])
+		}
+	}
+	if got.Get("X-Request-Id") != "abc" {
+		t.Error("unrelated header was stripped")
+	}
+}
@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/FABLOUSFALCON/localmesh/internal/events"
+)
+
+// QuietWindow is a daily window during which mDNS advertisement is paused.
+// Start and End are offsets from local midnight; a window whose End is
+// before its Start wraps past midnight (e.g. 22:00-06:00).
+type QuietWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Contains reports whether t falls inside the window
+func (q QuietWindow) Contains(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// inQuietHours reports whether advertisement should currently be paused
+func (g *Gateway) inQuietHours() bool {
+	return g.quietHours != nil && g.quietHours.Contains(g.now())
+}
+
+// runQuietHours pauses and resumes mDNS advertisement as the window opens and closes
+func (g *Gateway) runQuietHours() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.done:
+			return
+		case <-ticker.C:
+			g.applyQuietHours()
+		}
+	}
+}
+
+// applyQuietHours brings advertisement state in line with the quiet window
+func (g *Gateway) applyQuietHours() {
+	quiet := g.inQuietHours()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if quiet == g.paused {
+		return
+	}
+	g.paused = quiet
+
+	if quiet {
+		g.stopAdvertising()
+		g.logger.Info("mDNS advertisement paused for quiet hours")
+		g.events.Publish(events.MDNSPaused, nil)
+		return
+	}
+
+	if err := g.advertiseServer(); err != nil {
+		g.logger.Warn("failed to advertise server via mDNS", "error", err)
+	}
+	for name, svc := range g.services {
+		cmd, err := publishAddress(svc.Hostname, svc.IP)
+		if err != nil {
+			g.logger.Warn("failed to re-advertise service", "name", name, "error", err)
+			continue
+		}
+		g.processes[name] = cmd
+	}
+	g.logger.Info("mDNS advertisement resumed", "services", len(g.services))
+	g.events.Publish(events.MDNSResumed, map[string]int{"services": len(g.services)})
+}
+
+// stopAdvertising kills every running avahi process but keeps the service
+// entries so they can be re-advertised. Callers must hold g.mu.
+func (g *Gateway) stopAdvertising() {
+	if g.serverMDNS != nil && g.serverMDNS.Process != nil {
+		g.serverMDNS.Process.Kill()
+	}
+	g.serverMDNS = nil
+
+	for name, cmd := range g.processes {
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+		delete(g.processes, name)
+	}
+}
@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FABLOUSFALCON/localmesh/internal/events"
+)
+
+func TestQuietHoursPauseAndResume(t *testing.T) {
+	fakeAvahi(t)
+	bus := events.NewBus()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.Local)
+	g := newTestGateway(t, GatewayConfig{
+		ProxyPort:  8081,
+		Events:     bus,
+		QuietHours: &QuietWindow{Start: 22 * time.Hour, End: 6 * time.Hour},
+		Clock:      func() time.Time { return now },
+	})
+
+	ch, unsubscribe := bus.Subscribe("mdns")
+	defer unsubscribe()
+
+	if err := g.AdvertiseExternalService("wiki", "_http._tcp", 3000, "10.0.0.4", nil, false); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := func(paused bool, event string) {
+		t.Helper()
+		g.mu.RLock()
+		gotPaused, procs := g.paused, len(g.processes)
+		g.mu.RUnlock()
+
+		if gotPaused != paused {
+			t.Errorf("at %s paused = %v, want %v", now.Format("15:04"), gotPaused, paused)
+		}
+		if wantProcs := map[bool]int{true: 0, false: 1}[paused]; procs != wantProcs {
+			t.Errorf("at %s %d publishers running, want %d", now.Format("15:04"), procs, wantProcs)
+		}
+		select {
+		case ev := <-ch:
+			if ev.Type != event {
+				t.Errorf("at %s got %s event, want %s", now.Format("15:04"), ev.Type, event)
+			}
+		default:
+			if event != "" {
+				t.Errorf("at %s no %s event", now.Format("15:04"), event)
+			}
+		}
+	}
+
+	g.applyQuietHours()
+	expect(false, "")
+
+	now = now.Add(11 * time.Hour) // 23:00
+	g.applyQuietHours()
+	expect(true, events.MDNSPaused)
+
+	now = now.Add(6 * time.Hour) // 05:00 next day, still inside the window
+	g.applyQuietHours()
+	expect(true, "")
+
+	now = now.Add(time.Hour) // 06:00
+	g.applyQuietHours()
+	expect(false, events.MDNSResumed)
+
+	g.mu.RLock()
+	_, ok := g.services["wiki"]
+	g.mu.RUnlock()
+	if !ok {
+		t.Error("service dropped while paused")
+	}
+}
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is how long an untouched client bucket is kept
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimitConfig configures per-client token-bucket rate limiting
+type RateLimitConfig struct {
+	Limit  int           // Requests allowed per Window (0 disables limiting)
+	Burst  int           // Bucket capacity (defaults to Limit)
+	Window time.Duration // Period Limit applies to (default one minute)
+	Zones  []ZoneLimit   // Per-zone overrides, first matching subnet wins
+}
+
+// ZoneLimit overrides the request limit for clients inside a zone's subnets
+type ZoneLimit struct {
+	Zone    string
+	Subnets []*net.IPNet
+	Limit   int
+}
+
+// rateLimiter keeps a token bucket per client IP
+type rateLimiter struct {
+	cfg RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64 // tokens per second
+	last     time.Time
+}
+
+func newRateLimiter(cfg RateLimitConfig, now func() time.Time) *rateLimiter {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	return &rateLimiter{
+		cfg:       cfg,
+		now:       now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: now(),
+	}
+}
+
+// limitFor returns the zone and request limit that apply to ip
+func (l *rateLimiter) limitFor(ip net.IP) (string, int) {
+	for _, z := range l.cfg.Zones {
+		for _, subnet := range z.Subnets {
+			if ip != nil && subnet.Contains(ip) {
+				return z.Zone, z.Limit
+			}
+		}
+	}
+	return "", l.cfg.Limit
+}
+
+// allow takes a token for key, returning how long to wait when none is left
+func (l *rateLimiter) allow(key string, limit int) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > idleBucketTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		capacity := float64(l.cfg.Burst)
+		if capacity <= 0 || capacity > float64(limit) {
+			capacity = float64(limit)
+		}
+		b = &bucket{
+			tokens:   capacity,
+			capacity: capacity,
+			rate:     float64(limit) / l.cfg.Window.Seconds(),
+			last:     now,
+		}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// rateLimit rejects clients that exceed their bucket with 429 and Retry-After
+func (g *Gateway) rateLimit(next http.Handler) http.Handler {
+	if g.limiter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		zone, limit := g.limiter.limitFor(net.ParseIP(host))
+		if limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ok, wait := g.limiter.allow(host, limit)
+		if !ok {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			g.logger.Debug("rate limited", "client", host, "zone", zone, "retry_after", retryAfter)
+			g.jsonError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
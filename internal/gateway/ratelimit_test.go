@@ -0,0 +1,100 @@
+package gateway
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefills(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(RateLimitConfig{Limit: 60, Burst: 3}, func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("10.0.0.9", 60); !ok {
+			t.Fatalf("request %d denied within burst", i+1)
+		}
+	}
+
+	ok, wait := l.allow("10.0.0.9", 60)
+	if ok {
+		t.Fatal("request allowed with an empty bucket")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("wait = %v, want (0, 1s]", wait)
+	}
+	if ok, _ := l.allow("10.0.0.10", 60); !ok {
+		t.Error("another client shares the exhausted bucket")
+	}
+
+	// 60 per minute refills one token a second
+	now = now.Add(time.Second)
+	if ok, _ := l.allow("10.0.0.9", 60); !ok {
+		t.Error("bucket did not refill after one second")
+	}
+	if ok, _ := l.allow("10.0.0.9", 60); ok {
+		t.Error("bucket refilled more than one token")
+	}
+
+	// A long idle period refills only up to the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("10.0.0.9", 60); !ok {
+			t.Fatalf("request %d denied after refill", i+1)
+		}
+	}
+	if ok, _ := l.allow("10.0.0.9", 60); ok {
+		t.Error("bucket refilled past its burst")
+	}
+}
+
+func TestRateLimitZoneOverride(t *testing.T) {
+	_, lab, _ := net.ParseCIDR("10.1.0.0/16")
+	l := newRateLimiter(RateLimitConfig{
+		Limit: 60,
+		Zones: []ZoneLimit{{Zone: "lab", Subnets: []*net.IPNet{lab}, Limit: 600}},
+	}, time.Now)
+
+	if zone, limit := l.limitFor(net.ParseIP("10.1.2.3")); zone != "lab" || limit != 600 {
+		t.Errorf("limitFor(lab client) = %q, %d", zone, limit)
+	}
+	if zone, limit := l.limitFor(net.ParseIP("10.2.2.3")); zone != "" || limit != 60 {
+		t.Errorf("limitFor(other client) = %q, %d", zone, limit)
+	}
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	g := newTestGateway(t, GatewayConfig{
+		ProxyPort: 8081,
+		RateLimit: &RateLimitConfig{Limit: 1, Window: 30 * time.Second},
+		Clock:     func() time.Time { return now },
+	})
+	h := g.rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "10.0.0.9:51234"
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	if rec := get(); rec.Code != http.StatusOK {
+		t.Fatalf("first request = %d", rec.Code)
+	}
+	rec := get()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+
+	now = now.Add(30 * time.Second)
+	if rec := get(); rec.Code != http.StatusOK {
+		t.Errorf("request after Retry-After = %d", rec.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxRetryBackoff caps the delay between proxy retries
+const maxRetryBackoff = time.Second
+
+// retryTransport retries idempotent proxied requests that fail at the
+// transport level or get a 502 from the backend, with exponential backoff.
+// Other methods are never retried so side effects can't be applied twice.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	backoff time.Duration
+	onRetry func(req *http.Request, attempt int, reason string)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.retries <= 0 || !isRetryable(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	delay := t.backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+
+		var reason string
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case resp.StatusCode == http.StatusBadGateway:
+			reason = resp.Status
+		default:
+			return resp, nil
+		}
+
+		if attempt > t.retries {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		if t.onRetry != nil {
+			t.onRetry(req, attempt, reason)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		delay = min(delay*2, maxRetryBackoff)
+	}
+}
+
+// isRetryable reports whether req can safely be sent again
+func isRetryable(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody
+}
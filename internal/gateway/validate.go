@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// serviceNamePattern restricts service names to a single DNS label, since
+// each name is published as <name>.local and matched against Host headers
+var serviceNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// serviceNameRule explains serviceNamePattern in validation errors
+const serviceNameRule = "must be 1-63 lowercase letters, digits or hyphens, not starting or ending with a hyphen"
+
+// validServiceName reports whether name can be used as a service hostname
+func validServiceName(name string) bool {
+	return serviceNamePattern.MatchString(name)
+}
+
+// FieldError describes a problem with a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every field problem found in a request so
+// callers can report them all at once
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// validateRegistration checks a service registration request, returning
+// nil when it is valid
+func validateRegistration(name string, port int, ip string) error {
+	var errs ValidationError
+
+	if name == "" {
+		errs.add("name", "is required")
+	} else if !validServiceName(name) {
+		errs.add("name", serviceNameRule)
+	}
+	if port <= 0 || port > 65535 {
+		errs.add("port", "must be between 1 and 65535")
+	}
+	if ip != "" && net.ParseIP(ip) == nil {
+		errs.add("ip", "must be a valid IP address")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestRegisterRejectsInvalidNames(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081})
+
+	for _, name := range []string{"wiki/admin", "wiki.local", "../etc", "Wiki", "-wiki", ""} {
+		rec := postJSON(g, "/api/v1/services/register", fmt.Sprintf(`{"name":%q,"port":3000}`, name))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("register %q = %d, want 400", name, rec.Code)
+		}
+	}
+	if len(g.services) != 0 {
+		t.Errorf("invalid names registered: %v", g.services)
+	}
+}
+
+func TestProxyValidatesHostLabel(t *testing.T) {
+	fakeAvahi(t)
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081})
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+	host, port, _ := net.SplitHostPort(backend.Listener.Addr().String())
+
+	rec := postJSON(g, "/api/v1/services/register", fmt.Sprintf(`{"name":"room-101","port":%s,"ip":%q}`, port, host))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("register room-101 = %d: %s", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		host string
+		want int
+	}{
+		{"room-101.local", http.StatusOK},
+		{"room-101.local:8081", http.StatusOK},
+		{"room_101.local", http.StatusBadRequest},
+		{"a.room-101.local", http.StatusBadRequest},
+		{"-room.local", http.StatusBadRequest},
+		{"gym.local", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = tt.host
+		g.serveProxy(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Host %q = %d, want %d", tt.host, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestRegisterReportsEveryFieldError(t *testing.T) {
+	g := newTestGateway(t, GatewayConfig{ProxyPort: 8081})
+
+	tests := []struct {
+		body   string
+		fields []string
+	}{
+		{`{"name":"Bad Name","port":0}`, []string{"name", "port"}},
+		{`{"name":"wiki","port":70000,"ip":"10.0.0"}`, []string{"port", "ip"}},
+		{`{"port":-1,"ip":"nowhere"}`, []string{"name", "port", "ip"}},
+	}
+
+	for _, tt := range tests {
+		rec := postJSON(g, "/api/v1/services/register", tt.body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", tt.body, rec.Code)
+			continue
+		}
+
+		var resp struct {
+			Fields ValidationError `json:"fields"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, fe := range resp.Fields {
+			got = append(got, fe.Field)
+		}
+		if !slices.Equal(got, tt.fields) {
+			t.Errorf("%s: fields %q, want %q", tt.body, got, tt.fields)
+		}
+	}
+}